@@ -4,7 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -20,6 +20,11 @@ func showBookmarkMenu(reader *bufio.Reader) error {
 		fmt.Println("  2. 添加收藏")
 		fmt.Println("  3. 执行收藏")
 		fmt.Println("  4. 删除收藏")
+		fmt.Println("  5. 筛选收藏")
+		fmt.Println("  6. 编辑收藏")
+		fmt.Println("  7. 导入收藏")
+		fmt.Println("  8. 导出收藏")
+		fmt.Println("  9. 添加工作流")
 		fmt.Println("  0. 返回主菜单")
 		fmt.Printf("\n  %s", voice.Line("menu_prompt"))
 
@@ -31,95 +36,39 @@ func showBookmarkMenu(reader *bufio.Reader) error {
 
 		switch choice {
 		case "1":
-			items, err := bookmark.List()
-			if err != nil {
-				return err
-			}
-			printWhite("\n  #  NAME         COMMAND")
-			for i, item := range items {
-				fmt.Printf("  %-3d %-12s %s\n", i+1, item.Name, item.Command)
-			}
-			printMagenta(voice.Line("bookmark_list_done"))
-			if err := waitForEnter(reader); err != nil {
+			if err := showBookmarks(reader); err != nil {
 				return err
 			}
 		case "2":
-			fmt.Printf("\n  %s", voice.Line("bookmark_add_name_prompt"))
-			name, err := reader.ReadString('\n')
-			if err != nil {
+			if err := addBookmark(reader); err != nil {
 				return err
 			}
-			fmt.Printf("  %s", voice.Line("bookmark_add_cmd_prompt"))
-			cmdLine, err := reader.ReadString('\n')
-			if err != nil {
+		case "3":
+			if err := executeBookmark(reader); err != nil {
 				return err
 			}
-			if err := bookmark.Add(strings.TrimSpace(name), strings.TrimSpace(cmdLine)); err != nil {
-				printRed(err.Error())
-			} else {
-				printGreen(voice.Line("bookmark_add_success"))
-			}
-			if err := waitForEnter(reader); err != nil {
+		case "4":
+			if err := deleteBookmark(reader); err != nil {
 				return err
 			}
-		case "3":
-			fmt.Printf("\n  %s", voice.Line("bookmark_exec_prompt"))
-			input, err := reader.ReadString('\n')
-			if err != nil {
+		case "5":
+			if err := filterBookmarks(reader); err != nil {
 				return err
 			}
-			cmdLine, err := bookmark.Get(strings.TrimSpace(input))
-			if err != nil {
-				printRed(err.Error())
-				if err := waitForEnter(reader); err != nil {
-					return err
-				}
-				continue
-			}
-			cmd := exec.Command("/bin/sh", "-c", cmdLine)
-			cmd.Stdin = os.Stdin
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			if err := cmd.Run(); err != nil {
-				printRed(err.Error())
-			} else {
-				printMagenta(voice.Line("bookmark_exec_success"))
-			}
-			if err := waitForEnter(reader); err != nil {
+		case "6":
+			if err := editBookmark(reader); err != nil {
 				return err
 			}
-		case "4":
-			fmt.Printf("\n  %s", voice.Line("bookmark_delete_prompt"))
-			input, err := reader.ReadString('\n')
-			if err != nil {
+		case "7":
+			if err := importBookmarks(reader); err != nil {
 				return err
 			}
-			index, err := strconv.Atoi(strings.TrimSpace(input))
-			if err != nil {
-				printRed(voice.Line("invalid_index"))
-				if err := waitForEnter(reader); err != nil {
-					return err
-				}
-				continue
-			}
-			fmt.Printf("  %s", voice.Line("bookmark_delete_confirm"))
-			confirm, err := reader.ReadString('\n')
-			if err != nil {
+		case "8":
+			if err := exportBookmarks(reader); err != nil {
 				return err
 			}
-			if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
-				printYellow(voice.Line("bookmark_delete_cancel"))
-				if err := waitForEnter(reader); err != nil {
-					return err
-				}
-				continue
-			}
-			if err := bookmark.Delete(index); err != nil {
-				printRed(err.Error())
-			} else {
-				printGreen(voice.Line("bookmark_delete_success"))
-			}
-			if err := waitForEnter(reader); err != nil {
+		case "9":
+			if err := addWorkflow(reader); err != nil {
 				return err
 			}
 		case "0":
@@ -132,3 +81,432 @@ func showBookmarkMenu(reader *bufio.Reader) error {
 		}
 	}
 }
+
+func showBookmarks(reader *bufio.Reader) error {
+	items, err := bookmark.List()
+	if err != nil {
+		return err
+	}
+	project, found, err := bookmark.CurrentProject()
+	if err != nil {
+		printRed(err.Error())
+	}
+	if len(items) == 0 && (!found || len(project.Items) == 0) {
+		printYellow(voice.Line("bookmark_empty"))
+		return waitForEnter(reader)
+	}
+	all := bookmark.Apply(items, bookmark.Filter{})
+	for _, folder := range append(bookmark.Folders(items), "") {
+		entries := make([]bookmark.Entry, 0)
+		for _, entry := range all {
+			if entry.Item.Folder == folder {
+				entries = append(entries, entry)
+			}
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		title := folder
+		if title == "" {
+			title = voice.Line("bookmark_no_folder")
+		}
+		printCyan(fmt.Sprintf("\n  [%s]", title))
+		printBookmarkEntries(entries, false)
+	}
+	if found && len(project.Items) > 0 {
+		printCyan(fmt.Sprintf("\n  [%s] %s", voice.Line("bookmark_project_title"), project.Path))
+		printBookmarkEntries(bookmark.Apply(project.Items, bookmark.Filter{}), true)
+	}
+	printMagenta(voice.Line("bookmark_list_done"))
+	return waitForEnter(reader)
+}
+
+func filterBookmarks(reader *bufio.Reader) error {
+	items, err := bookmark.List()
+	if err != nil {
+		return err
+	}
+	project, _, err := bookmark.CurrentProject()
+	if err != nil {
+		printRed(err.Error())
+	}
+	combined := append(append([]bookmark.Item{}, items...), project.Items...)
+	if tags := bookmark.Tags(combined); len(tags) > 0 {
+		printWhite("  TAGS    " + strings.Join(tags, ", "))
+	}
+	if folders := bookmark.Folders(combined); len(folders) > 0 {
+		printWhite("  FOLDERS " + strings.Join(folders, ", "))
+	}
+	fmt.Printf("\n  %s", voice.Line("bookmark_filter_tag_prompt"))
+	tag, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  %s", voice.Line("bookmark_filter_folder_prompt"))
+	folder, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	fmt.Println(voice.Line("bookmark_sort_condition"))
+	fmt.Printf("  %s", voice.Line("finder_condition_input"))
+	sortInput, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	filter := bookmark.Filter{Tag: strings.TrimSpace(tag), Folder: strings.TrimSpace(folder)}
+	switch strings.TrimSpace(sortInput) {
+	case "2":
+		filter.Sort = bookmark.SortUsage
+	case "3":
+		filter.Sort = bookmark.SortRecent
+	}
+	entries := bookmark.Apply(items, filter)
+	projectEntries := bookmark.Apply(project.Items, filter)
+	if len(entries) == 0 && len(projectEntries) == 0 {
+		printYellow(voice.Line("no_results"))
+		return waitForEnter(reader)
+	}
+	if len(entries) > 0 {
+		printBookmarkEntries(entries, false)
+	}
+	if len(projectEntries) > 0 {
+		printCyan(fmt.Sprintf("\n  [%s] %s", voice.Line("bookmark_project_title"), project.Path))
+		printBookmarkEntries(projectEntries, true)
+	}
+	printMagenta(voice.Line("bookmark_list_done"))
+	return waitForEnter(reader)
+}
+
+func printBookmarkEntries(entries []bookmark.Entry, project bool) {
+	printWhite("\n  #   NAME         USES  COMMAND")
+	for _, entry := range entries {
+		item := entry.Item
+		key := strconv.Itoa(entry.Index)
+		if project {
+			key = bookmark.ProjectKey(entry.Index)
+		}
+		command := item.Command
+		if item.IsWorkflow() {
+			command = voice.Linef("workflow_summary", len(item.Steps))
+		}
+		fmt.Printf("  %-3s %-12s %-5d %s\n", key, item.Name, item.UseCount, command)
+		details := make([]string, 0)
+		if item.Description != "" {
+			details = append(details, item.Description)
+		}
+		if len(item.Tags) > 0 {
+			details = append(details, "#"+strings.Join(item.Tags, " #"))
+		}
+		if item.Dir != "" {
+			details = append(details, "@ "+item.Dir)
+		}
+		if item.LastUsed != "" {
+			details = append(details, item.LastUsed)
+		}
+		if len(details) > 0 {
+			printBlue("                         " + strings.Join(details, "  "))
+		}
+		if item.IsWorkflow() {
+			printWorkflowSteps(item)
+		}
+	}
+}
+
+func addBookmark(reader *bufio.Reader) error {
+	fmt.Printf("\n  %s", voice.Line("bookmark_add_name_prompt"))
+	name, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  %s", voice.Line("bookmark_add_cmd_prompt"))
+	cmdLine, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  %s", voice.Line("bookmark_add_desc_prompt"))
+	description, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  %s", voice.Line("bookmark_add_tags_prompt"))
+	tags, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  %s", voice.Line("bookmark_add_folder_prompt"))
+	folder, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  %s", voice.Line("bookmark_add_dir_prompt"))
+	dir, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	item := bookmark.Item{
+		Name:        name,
+		Command:     cmdLine,
+		Description: description,
+		Tags:        bookmark.ParseTags(tags),
+		Folder:      folder,
+		Dir:         dir,
+	}
+	if err := bookmark.Add(item); err != nil {
+		printRed(err.Error())
+	} else {
+		printGreen(voice.Line("bookmark_add_success"))
+	}
+	return waitForEnter(reader)
+}
+
+func editBookmark(reader *bufio.Reader) error {
+	fmt.Printf("\n  %s", voice.Line("bookmark_edit_prompt"))
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	item, err := bookmark.Get(strings.TrimSpace(input))
+	if err != nil {
+		printRed(err.Error())
+		return waitForEnter(reader)
+	}
+	if item.Source != "" {
+		printYellow(voice.Linef("bookmark_project_readonly", item.Source))
+		return waitForEnter(reader)
+	}
+	printMagenta(voice.Line("bookmark_edit_hint"))
+	updated := item
+	fields := []struct {
+		label string
+		value *string
+	}{
+		{"NAME", &updated.Name},
+		{"COMMAND", &updated.Command},
+		{"DESCRIPTION", &updated.Description},
+		{"FOLDER", &updated.Folder},
+		{"DIR", &updated.Dir},
+	}
+	for _, field := range fields {
+		value, ok, err := editLine(reader, fmt.Sprintf("  %-12s ", field.label), *field.value)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			printYellow(voice.Line("bookmark_edit_cancel"))
+			return waitForEnter(reader)
+		}
+		*field.value = value
+	}
+	tags, ok, err := editLine(reader, fmt.Sprintf("  %-12s ", "TAGS"), strings.Join(item.Tags, ", "))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		printYellow(voice.Line("bookmark_edit_cancel"))
+		return waitForEnter(reader)
+	}
+	updated.Tags = bookmark.ParseTags(tags)
+	if err := bookmark.Update(item.Name, updated); err != nil {
+		printRed(err.Error())
+	} else {
+		printGreen(voice.Line("bookmark_edit_success"))
+	}
+	return waitForEnter(reader)
+}
+
+func executeBookmark(reader *bufio.Reader) error {
+	fmt.Printf("\n  %s", voice.Line("bookmark_exec_prompt"))
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	item, err := bookmark.Get(strings.TrimSpace(input))
+	if err != nil {
+		printRed(err.Error())
+		return waitForEnter(reader)
+	}
+	if item.IsWorkflow() {
+		if item.Source == "" {
+			if err := bookmark.MarkUsed(item.Name); err != nil {
+				printRed(err.Error())
+			}
+		}
+		if err := runWorkflow(reader, item); err != nil {
+			return err
+		}
+		return waitForEnter(reader)
+	}
+	cmdLine, ok, err := prepareCommand(reader, item.Command)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return waitForEnter(reader)
+	}
+	if item.Source == "" {
+		if err := bookmark.MarkUsed(item.Name); err != nil {
+			printRed(err.Error())
+		}
+	}
+	if err := executeShellCommand("bookmark", item.Name, cmdLine, bookmark.ResolveDir(item.Dir)); err != nil {
+		printRed(err.Error())
+	} else {
+		printMagenta(voice.Line("bookmark_exec_success"))
+	}
+	return waitForEnter(reader)
+}
+
+func deleteBookmark(reader *bufio.Reader) error {
+	fmt.Printf("\n  %s", voice.Line("bookmark_delete_prompt"))
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	index, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil {
+		printRed(voice.Line("invalid_index"))
+		return waitForEnter(reader)
+	}
+	fmt.Printf("  %s", voice.Line("bookmark_delete_confirm"))
+	confirm, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
+		printYellow(voice.Line("bookmark_delete_cancel"))
+		return waitForEnter(reader)
+	}
+	if err := bookmark.Delete(index); err != nil {
+		printRed(err.Error())
+	} else {
+		printGreen(voice.Line("bookmark_delete_success"))
+	}
+	return waitForEnter(reader)
+}
+
+func importBookmarks(reader *bufio.Reader) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	sources := bookmark.DetectSources(wd)
+	if len(sources) > 0 {
+		printWhite("\n  #   SOURCE")
+		for i, source := range sources {
+			fmt.Printf("  %-3d %s\n", i+1, source)
+		}
+	}
+	fmt.Printf("\n  %s", voice.Line("bookmark_import_prompt"))
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	input = strings.TrimSpace(input)
+	paths := sources
+	if input != "" {
+		paths = []string{input}
+		if index, err := strconv.Atoi(input); err == nil {
+			if index <= 0 || index > len(sources) {
+				printRed(voice.Line("invalid_index"))
+				return waitForEnter(reader)
+			}
+			paths = []string{sources[index-1]}
+		}
+	}
+	if len(paths) == 0 {
+		printYellow(voice.Line("bookmark_import_empty"))
+		return waitForEnter(reader)
+	}
+
+	items := make([]bookmark.Item, 0)
+	for _, path := range paths {
+		parsed, err := bookmark.ImportFile(path)
+		if err != nil {
+			printRed(fmt.Sprintf("%s: %s", path, err.Error()))
+			continue
+		}
+		items = append(items, parsed...)
+	}
+	if len(items) == 0 {
+		printYellow(voice.Line("bookmark_import_empty"))
+		return waitForEnter(reader)
+	}
+	printWhite("\n  NAME                 COMMAND")
+	for _, item := range items {
+		fmt.Printf("  %-20s %s\n", item.Name, truncateText(item.Command, 60))
+	}
+	fmt.Printf("\n  %s", voice.Linef("bookmark_import_confirm", len(items)))
+	confirm, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
+		printYellow(voice.Line("bookmark_import_cancel"))
+		return waitForEnter(reader)
+	}
+	added, skipped, err := bookmark.Import(items)
+	if err != nil {
+		printRed(err.Error())
+	} else {
+		printGreen(voice.Linef("bookmark_import_success", added, skipped))
+	}
+	return waitForEnter(reader)
+}
+
+func exportBookmarks(reader *bufio.Reader) error {
+	items, err := bookmark.List()
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		printYellow(voice.Line("bookmark_empty"))
+		return waitForEnter(reader)
+	}
+	fmt.Println(voice.Line("bookmark_export_format"))
+	fmt.Printf("  %s", voice.Line("finder_condition_input"))
+	choice, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	format, name := bookmark.FormatAlias, "aliases.sh"
+	switch strings.TrimSpace(choice) {
+	case "1", "":
+	case "2":
+		format, name = bookmark.FormatJSON, "bookmarks-export.json"
+	case "3":
+		format, name = bookmark.FormatYAML, "bookmarks-export.yaml"
+	default:
+		printRed(voice.Line("invalid_option"))
+		return waitForEnter(reader)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	target := filepath.Join(home, ".sakibox", name)
+	fmt.Printf("  %s", voice.Linef("bookmark_export_path_prompt", target))
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if input = strings.TrimSpace(input); input != "" {
+		target = bookmark.ResolveDir(input)
+	}
+	data, err := bookmark.Export(items, format)
+	if err != nil {
+		printRed(err.Error())
+		return waitForEnter(reader)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(target, data, 0644); err != nil {
+		printRed(err.Error())
+		return waitForEnter(reader)
+	}
+	printGreen(voice.Linef("bookmark_export_success", len(items), target))
+	if format == bookmark.FormatAlias {
+		printYellow(voice.Linef("bookmark_export_source_hint", target))
+	}
+	return waitForEnter(reader)
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"sakibox/config"
+	"sakibox/internal/placeholder"
+	"sakibox/internal/runlog"
+	"sakibox/internal/voice"
+)
+
+func prepareCommand(reader *bufio.Reader, command string) (string, bool, error) {
+	printMagenta(voice.Line("command_edit_hint"))
+	edited, ok, err := editLine(reader, "  $ ", command)
+	if err != nil {
+		return "", false, err
+	}
+	if !ok || edited == "" {
+		printYellow(voice.Line("command_edit_cancel"))
+		return "", false, nil
+	}
+
+	values, ok, err := fillPlaceholders(reader, edited)
+	if err != nil || !ok {
+		return "", false, err
+	}
+	if len(values) == 0 {
+		return edited, true, nil
+	}
+	final := placeholder.Fill(edited, values)
+	printYellow(voice.Linef("command_preview", final))
+	return final, true, nil
+}
+
+func fillPlaceholders(reader *bufio.Reader, text string) (map[string]string, bool, error) {
+	values := make(map[string]string)
+	fields := placeholder.Parse(text)
+	if len(fields) == 0 {
+		return values, true, nil
+	}
+	last, err := placeholder.LastValues()
+	if err != nil {
+		return nil, false, err
+	}
+	printMagenta(voice.Line("placeholder_intro"))
+	for _, field := range fields {
+		fallback := field.Default
+		if value, ok := last[field.Name]; ok && value != "" {
+			fallback = value
+		}
+		label := field.Name
+		if fallback != "" {
+			label = fmt.Sprintf("%s [%s]", field.Name, fallback)
+		}
+		fmt.Printf("  %s", voice.Linef("placeholder_prompt", label))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, false, err
+		}
+		value := strings.TrimSpace(input)
+		if value == "" {
+			value = fallback
+		}
+		if value == "" {
+			printRed(voice.Line("placeholder_empty"))
+			return nil, false, nil
+		}
+		values[field.Name] = value
+	}
+	if err := placeholder.Remember(values); err != nil {
+		printRed(err.Error())
+	}
+	return values, true, nil
+}
+
+func executeShellCommand(source, name, command, dir string) error {
+	_, err := runShellCommand(runlog.Spec{Source: source, Name: name, Command: command, Dir: dir})
+	return err
+}
+
+func runShellCommand(spec runlog.Spec) (runlog.Run, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return runlog.Run{}, err
+	}
+	spec.Capture = cfg.CaptureOutput
+	run, err := runlog.Exec(spec)
+	printWhite(voice.Linef("run_summary", run.ExitCode, runlog.FormatDuration(run.Duration())))
+	return run, err
+}
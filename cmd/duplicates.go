@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"sakibox/internal/fileops"
+	"sakibox/internal/finder"
+	"sakibox/internal/voice"
+)
+
+func findDuplicates(reader *bufio.Reader) error {
+	path, err := promptPath(reader)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  %s", voice.Line("finder_dup_min_prompt"))
+	sizeInput, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	var minSize int64
+	if sizeInput = strings.TrimSpace(sizeInput); sizeInput != "" {
+		minSize, err = finder.ParseSize(sizeInput)
+		if err != nil {
+			printRed(err.Error())
+			return waitForEnter(reader)
+		}
+	}
+	printYellow(voice.Line("searching"))
+
+	var mu sync.Mutex
+	live := isTerminal()
+	ctx, stop := searchContext()
+	groups, err := finder.FindDuplicates(ctx, path, minSize, func(p finder.DuplicateProgress) {
+		if !live {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if p.Done {
+			fmt.Print("\r\033[K")
+			return
+		}
+		fmt.Printf("\r\033[K  %s", voice.Linef("finder_dup_progress", p.Files, p.Hashed, p.Total))
+	})
+	stop()
+	if errors.Is(err, context.Canceled) {
+		printYellow(voice.Line("finder_search_cancelled"))
+		return waitForEnter(reader)
+	}
+	if err != nil {
+		return err
+	}
+	if len(groups) == 0 {
+		printYellow(voice.Line("finder_dup_none"))
+		return waitForEnter(reader)
+	}
+
+	printDuplicateGroups(groups)
+	return showDuplicateActions(reader, groups)
+}
+
+func printDuplicateGroups(groups []finder.DuplicateGroup) {
+	var wasted int64
+	for i, group := range groups {
+		wasted += group.Wasted()
+		printWhite(fmt.Sprintf("\n  [%d] %s × %d  %s", i+1, finder.FormatSize(group.Size), len(group.Files), voice.Linef("finder_dup_wasted", finder.FormatSize(group.Wasted()))))
+		for _, file := range group.Files {
+			printBlue(fmt.Sprintf("      %-40s %s", file.Path, file.ModTime.Format("2006-01-02 15:04")))
+		}
+	}
+	printMagenta(voice.Linef("finder_dup_summary", len(groups), finder.FormatSize(wasted)))
+}
+
+func pruneDuplicates(groups []finder.DuplicateGroup, handled map[string]bool) []finder.DuplicateGroup {
+	remaining := make([]finder.DuplicateGroup, 0, len(groups))
+	for _, group := range groups {
+		files := make([]finder.DuplicateFile, 0, len(group.Files))
+		for _, file := range group.Files {
+			abs, err := filepath.Abs(file.Path)
+			if err != nil || !handled[abs] {
+				files = append(files, file)
+			}
+		}
+		if len(files) > 1 {
+			group.Files = files
+			remaining = append(remaining, group)
+		}
+	}
+	return remaining
+}
+
+func showDuplicateActions(reader *bufio.Reader, groups []finder.DuplicateGroup) error {
+	for {
+		fmt.Println("\n  h 硬链接到保留文件  d 删除重复（保留第一个）  n 只保留最新  u 撤销删除")
+		fmt.Printf("  %s", voice.Line("finder_actions_prompt"))
+		choice, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		choice = strings.ToLower(strings.TrimSpace(choice))
+		switch choice {
+		case "":
+			return nil
+		case "u":
+			batch, err := fileops.UndoTrash()
+			if len(batch.Items) > 0 {
+				printGreen(voice.Linef("finder_trash_undone", len(batch.Items)))
+			}
+			if err != nil {
+				printRed(err.Error())
+			}
+			continue
+		case "h", "d", "n":
+		default:
+			printRed(voice.Line("invalid_option"))
+			continue
+		}
+
+		fmt.Printf("  %s", voice.Line("finder_dup_select_prompt"))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		indexes, err := parseSelection(input, len(groups))
+		if err != nil {
+			printRed(err.Error())
+			continue
+		}
+		if len(indexes) == 0 {
+			printYellow(voice.Line("finder_select_empty"))
+			continue
+		}
+
+		verb := "删除"
+		if choice == "h" {
+			verb = "链接"
+		}
+		type plan struct {
+			keep   string
+			others []string
+		}
+		plans := make([]plan, 0, len(indexes))
+		var freed int64
+		for _, index := range indexes {
+			group := groups[index]
+			keep := 0
+			if choice == "n" {
+				keep = group.Newest()
+			}
+			next := plan{keep: group.Files[keep].Path}
+			for i, file := range group.Files {
+				if i != keep {
+					next.others = append(next.others, file.Path)
+				}
+			}
+			plans = append(plans, next)
+			freed += group.Wasted()
+		}
+
+		printYellow(voice.Linef("finder_dup_preview", len(plans), finder.FormatSize(freed)))
+		for _, p := range plans {
+			printGreen(fmt.Sprintf("  保留  %s", p.keep))
+			for _, other := range p.others {
+				fmt.Printf("  %s  %s\n", verb, other)
+			}
+		}
+		fmt.Printf("  %s", voice.Line("finder_action_confirm"))
+		confirm, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
+			printYellow(voice.Line("finder_action_cancel"))
+			continue
+		}
+
+		handled := make(map[string]bool)
+		if choice == "h" {
+			for _, p := range plans {
+				done, err := fileops.Hardlink(p.keep, p.others)
+				if err != nil {
+					printRed(err.Error())
+				}
+				for _, path := range done {
+					if abs, err := filepath.Abs(path); err == nil {
+						handled[abs] = true
+					}
+				}
+			}
+			if len(handled) > 0 {
+				printGreen(voice.Linef("finder_dup_linked", len(handled)))
+			}
+		} else {
+			paths := make([]string, 0)
+			for _, p := range plans {
+				paths = append(paths, p.others...)
+			}
+			batch, err := fileops.Trash(paths)
+			if err != nil {
+				printRed(err.Error())
+			}
+			for _, item := range batch.Items {
+				handled[item.Original] = true
+			}
+			if len(batch.Items) > 0 {
+				printGreen(voice.Linef("finder_trash_done", len(batch.Items)))
+			}
+		}
+		if len(handled) == 0 {
+			continue
+		}
+		groups = pruneDuplicates(groups, handled)
+		if len(groups) == 0 {
+			printMagenta(voice.Line("finder_dup_all_handled"))
+			return nil
+		}
+		printDuplicateGroups(groups)
+	}
+}
@@ -0,0 +1,369 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"sakibox/internal/bookmark"
+	"sakibox/internal/fileops"
+	"sakibox/internal/finder"
+	"sakibox/internal/voice"
+)
+
+type actionTarget struct {
+	path string
+	line int
+}
+
+func resultTargets(results []finder.Result) []actionTarget {
+	targets := make([]actionTarget, 0, len(results))
+	seen := make(map[string]bool)
+	for _, item := range results {
+		path, _, _ := finder.SplitArchivePath(item.Path)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		targets = append(targets, actionTarget{path: path})
+	}
+	return targets
+}
+
+func contentTargets(results []finder.ContentResult) []actionTarget {
+	targets := make([]actionTarget, 0)
+	seen := make(map[string]bool)
+	for _, item := range results {
+		path, _, inArchive := finder.SplitArchivePath(item.Path)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		target := actionTarget{path: path, line: item.Line}
+		if inArchive {
+			target.line = 0
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+func showResultActions(reader *bufio.Reader, all []actionTarget, listed bool) error {
+	gone := make(map[string]bool)
+	targets := all
+	for len(targets) > 0 {
+		fmt.Println("\n  o 打开  d 删除  m 移动  c 复制  t 打包 tar.gz  z 打包 zip  s 统计大小  f 跟踪日志  u 撤销删除")
+		fmt.Printf("  %s", voice.Line("finder_actions_prompt"))
+		choice, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		choice = strings.ToLower(strings.TrimSpace(choice))
+		switch choice {
+		case "":
+			return nil
+		case "u":
+			batch, err := fileops.UndoTrash()
+			if len(batch.Items) > 0 {
+				printGreen(voice.Linef("finder_trash_undone", len(batch.Items)))
+				for _, item := range batch.Items {
+					delete(gone, item.Original)
+				}
+				targets, listed = remainingTargets(all, gone), false
+			}
+			if err != nil {
+				printRed(err.Error())
+			}
+			continue
+		case "o", "d", "m", "c", "t", "z", "s", "f":
+		default:
+			printRed(voice.Line("invalid_option"))
+			continue
+		}
+
+		if !listed {
+			printTargets(targets)
+			listed = true
+		}
+		selected, err := promptSelection(reader, targets)
+		if err != nil {
+			return err
+		}
+		if len(selected) == 0 {
+			continue
+		}
+		paths := targetPaths(selected)
+
+		switch choice {
+		case "o":
+			for _, target := range selected {
+				openInEditor(target)
+			}
+		case "f":
+			files := regularFiles(paths)
+			if len(files) == 0 {
+				printRed(voice.Line("tail_no_files"))
+			} else if err := followFiles(files); err != nil {
+				printRed(err.Error())
+			}
+		case "s":
+			total, files, err := fileops.TotalSize(paths)
+			if err != nil {
+				printRed(err.Error())
+				continue
+			}
+			printGreen(voice.Linef("finder_total_size", files, finder.FormatSize(total)))
+		case "d":
+			if ok, err := confirmAction(reader, "移入回收站", paths, func(path string) string {
+				return "  " + path
+			}); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			batch, err := fileops.Trash(paths)
+			if err != nil {
+				printRed(err.Error())
+			}
+			if len(batch.Items) > 0 {
+				printGreen(voice.Linef("finder_trash_done", len(batch.Items)))
+			}
+			for _, item := range batch.Items {
+				gone[item.Original] = true
+			}
+		case "m", "c":
+			fmt.Printf("  %s", voice.Line("finder_target_prompt"))
+			target, err := reader.ReadString('\n')
+			if err != nil {
+				return err
+			}
+			target = bookmark.ResolveDir(strings.TrimSpace(target))
+			if target == "" {
+				printRed(voice.Line("finder_target_empty"))
+				continue
+			}
+			verb := "移动"
+			if choice == "c" {
+				verb = "复制"
+			}
+			if ok, err := confirmAction(reader, verb, paths, func(path string) string {
+				dst := fileops.Destination(path, target)
+				line := fmt.Sprintf("  %s → %s", path, dst)
+				if _, err := os.Lstat(dst); err == nil {
+					line += "  " + voice.Linef("fileops_exists", dst)
+				}
+				return line
+			}); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			if choice == "c" {
+				done, err := fileops.Copy(paths, target)
+				if err != nil {
+					printRed(err.Error())
+				}
+				if len(done) > 0 {
+					printGreen(voice.Linef("finder_copy_done", len(done), target))
+				}
+				break
+			}
+			done, err := fileops.Move(paths, target)
+			if err != nil {
+				printRed(err.Error())
+			}
+			if len(done) > 0 {
+				printGreen(voice.Linef("finder_move_done", len(done), target))
+			}
+			for _, dst := range done {
+				for _, path := range paths {
+					if fileops.Destination(path, target) == dst {
+						gone[absPath(path)] = true
+					}
+				}
+			}
+		case "t", "z":
+			format := fileops.FormatTarGz
+			if choice == "z" {
+				format = fileops.FormatZip
+			}
+			fallback := fmt.Sprintf("sakibox-%s.%s", time.Now().Format("20060102-150405"), format)
+			fmt.Printf("  %s", voice.Linef("finder_archive_prompt", fallback))
+			dest, err := reader.ReadString('\n')
+			if err != nil {
+				return err
+			}
+			dest = bookmark.ResolveDir(strings.TrimSpace(dest))
+			if dest == "" {
+				dest = fallback
+			}
+			if ok, err := confirmAction(reader, "打包到 "+dest+" ", paths, func(path string) string {
+				return "  " + path
+			}); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			count, err := fileops.Archive(paths, dest, format)
+			if err != nil {
+				printRed(err.Error())
+				continue
+			}
+			if count > 0 {
+				printGreen(voice.Linef("finder_archive_done", count, dest))
+			}
+		}
+
+		if choice == "d" || choice == "m" {
+			targets, listed = remainingTargets(all, gone), false
+		}
+	}
+	return nil
+}
+
+func regularFiles(paths []string) []string {
+	files := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+func printTargets(targets []actionTarget) {
+	printWhite("\n  #    PATH")
+	for i, target := range targets {
+		label := target.path
+		if target.line > 0 {
+			label = fmt.Sprintf("%s:%d", target.path, target.line)
+		}
+		printBlue(fmt.Sprintf("  %-4d %s", i+1, label))
+	}
+}
+
+func promptSelection(reader *bufio.Reader, targets []actionTarget) ([]actionTarget, error) {
+	fmt.Printf("  %s", voice.Line("finder_select_prompt"))
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := parseSelection(input, len(targets))
+	if err != nil {
+		printRed(err.Error())
+		return nil, nil
+	}
+	if len(indexes) == 0 {
+		printYellow(voice.Line("finder_select_empty"))
+		return nil, nil
+	}
+	selected := make([]actionTarget, 0, len(indexes))
+	for _, index := range indexes {
+		selected = append(selected, targets[index])
+	}
+	return selected, nil
+}
+
+func parseSelection(input string, count int) ([]int, error) {
+	seen := make(map[int]bool)
+	indexes := make([]int, 0)
+	add := func(index int) {
+		if !seen[index] {
+			seen[index] = true
+			indexes = append(indexes, index)
+		}
+	}
+	for _, token := range strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return r == ',' || r == '，' || unicode.IsSpace(r)
+	}) {
+		if token == "a" || token == "all" || token == "*" {
+			for i := 0; i < count; i++ {
+				add(i)
+			}
+			continue
+		}
+		from, to, isRange := strings.Cut(token, "-")
+		if !isRange {
+			to = from
+		}
+		start, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, errors.New(voice.Linef("finder_select_invalid", token))
+		}
+		end, err := strconv.Atoi(to)
+		if err != nil || start < 1 || end > count || start > end {
+			return nil, errors.New(voice.Linef("finder_select_invalid", token))
+		}
+		for i := start; i <= end; i++ {
+			add(i - 1)
+		}
+	}
+	return indexes, nil
+}
+
+func confirmAction(reader *bufio.Reader, verb string, paths []string, describe func(path string) string) (bool, error) {
+	total, _, err := fileops.TotalSize(paths)
+	if err != nil {
+		printRed(err.Error())
+	}
+	printYellow(voice.Linef("finder_action_preview", verb, len(paths), finder.FormatSize(total)))
+	for _, path := range paths {
+		fmt.Println(describe(path))
+	}
+	fmt.Printf("  %s", voice.Line("finder_action_confirm"))
+	confirm, err := reader.ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+	if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
+		printYellow(voice.Line("finder_action_cancel"))
+		return false, nil
+	}
+	return true, nil
+}
+
+func openInEditor(target actionTarget) {
+	cmd := exec.Command("/bin/sh", "-c", fileops.EditorCommand(target.path, target.line))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		printRed(err.Error())
+	}
+}
+
+func targetPaths(targets []actionTarget) []string {
+	paths := make([]string, 0, len(targets))
+	seen := make(map[string]bool)
+	for _, target := range targets {
+		if !seen[target.path] {
+			seen[target.path] = true
+			paths = append(paths, target.path)
+		}
+	}
+	return paths
+}
+
+func remainingTargets(all []actionTarget, gone map[string]bool) []actionTarget {
+	kept := make([]actionTarget, 0, len(all))
+	for _, target := range all {
+		if !gone[absPath(target.path)] {
+			kept = append(kept, target)
+		}
+	}
+	return kept
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
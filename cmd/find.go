@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"sakibox/config"
+	"sakibox/internal/fileops"
+	"sakibox/internal/finder"
+	"sakibox/internal/voice"
+)
+
+var watchExec string
+
+var findCmd = &cobra.Command{
+	Use:   "find",
+	Short: "file finder commands",
+}
+
+var findWatchCmd = &cobra.Command{
+	Use:          "watch <root> <query>",
+	Args:         cobra.MinimumNArgs(2),
+	SilenceUsage: true,
+	Short:        "stream create/modify/delete events for files matching a finder query",
+	Long: "Watch root recursively and print an event whenever a file matching the query\n" +
+		"is created, modified or deleted. The query uses the finder query language,\n" +
+		"e.g. 'ext:yaml path:deploy' or 'name:*.log content:/panic/'.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.EnsureConfig(); err != nil {
+			return err
+		}
+		query, err := finder.ParseQuery(strings.Join(args[1:], " "))
+		if err != nil {
+			return err
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		printMagenta(voice.Linef("finder_watch_started", args[0]))
+		runner := &watchRunner{command: watchExec, pending: make(map[string]finder.WatchEvent), quiet: make(map[string]time.Time)}
+		defer runner.wait()
+		return finder.Watch(ctx, args[0], query, func(event finder.WatchEvent) {
+			printWatchEvent(event)
+			if watchExec != "" && event.Op != finder.WatchOverflow {
+				runner.run(event)
+			}
+		})
+	},
+}
+
+func init() {
+	findWatchCmd.Flags().StringVar(&watchExec, "exec", "", "shell command to run on each event; {} is replaced with the quoted path")
+	findCmd.AddCommand(findWatchCmd)
+	rootCmd.AddCommand(findCmd)
+}
+
+func printWatchEvent(event finder.WatchEvent) {
+	if event.Op == finder.WatchOverflow {
+		printYellow(voice.Line("finder_watch_overflow"))
+		return
+	}
+	op := color.New(color.FgGreen)
+	switch event.Op {
+	case finder.WatchModify:
+		op = color.New(color.FgYellow)
+	case finder.WatchDelete:
+		op = color.New(color.FgRed)
+	}
+	fmt.Printf("%s %s %s\n",
+		color.New(color.FgHiBlack).Sprint(event.Time.Format("15:04:05")),
+		op.Sprintf("%-6s", event.Op),
+		event.Path)
+}
+
+const watchQuiet = time.Second
+
+type watchRunner struct {
+	command string
+	mu      sync.Mutex
+	busy    bool
+	running string
+	queue   []string
+	pending map[string]finder.WatchEvent
+	quiet   map[string]time.Time
+	done    sync.WaitGroup
+}
+
+func (r *watchRunner) run(event finder.WatchEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, queued := r.pending[event.Path]
+	switch {
+	case r.busy && event.Path == r.running || event.Time.Before(r.quiet[event.Path]):
+		printYellow(voice.Linef("finder_watch_skipped", event.Path))
+		return
+	case queued:
+		r.pending[event.Path] = event
+		printYellow(voice.Linef("finder_watch_coalesced", event.Path))
+		return
+	}
+	delete(r.quiet, event.Path)
+	r.pending[event.Path] = event
+	r.queue = append(r.queue, event.Path)
+	if r.busy {
+		return
+	}
+	r.busy = true
+	r.done.Add(1)
+	go r.drain()
+}
+
+func (r *watchRunner) drain() {
+	defer r.done.Done()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for len(r.queue) > 0 {
+		path := r.queue[0]
+		r.queue = r.queue[1:]
+		event := r.pending[path]
+		delete(r.pending, path)
+		r.running = path
+		r.mu.Unlock()
+		runWatchCommand(r.command, event)
+		r.mu.Lock()
+		r.quiet[path] = time.Now().Add(watchQuiet)
+	}
+	r.busy, r.running = false, ""
+}
+
+func (r *watchRunner) wait() {
+	r.mu.Lock()
+	r.queue = nil
+	clear(r.pending)
+	r.mu.Unlock()
+	r.done.Wait()
+}
+
+func runWatchCommand(command string, event finder.WatchEvent) {
+	cmd := exec.Command("/bin/sh", "-c", strings.ReplaceAll(command, "{}", fileops.ShellQuote(event.Path)))
+	cmd.Env = append(os.Environ(), "SAKIBOX_EVENT="+event.Op, "SAKIBOX_PATH="+event.Path)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		printRed(voice.Linef("finder_watch_exec_failed", err))
+	}
+}
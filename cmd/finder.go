@@ -2,8 +2,16 @@ package cmd
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
 
 	"github.com/fatih/color"
 
@@ -22,6 +30,13 @@ func showFinderMenu(reader *bufio.Reader) error {
 		fmt.Println("  4. 按大小查找")
 		fmt.Println("  5. 按修改时间查找")
 		fmt.Println("  6. 全局检索")
+		fmt.Println("  7. 搜索范围设置")
+		fmt.Println("  8. 组合查询")
+		fmt.Println("  9. 查找重复文件")
+		fmt.Println("  10. 磁盘占用分析")
+		fmt.Println("  11. 结构化查找（JSON / YAML / TOML）")
+		fmt.Println("  12. 搜索并替换")
+		fmt.Println("  13. 撤销上次替换")
 		fmt.Println("  0. 返回主菜单")
 		fmt.Printf("\n  %s", voice.Line("menu_prompt"))
 
@@ -56,6 +71,34 @@ func showFinderMenu(reader *bufio.Reader) error {
 			if err := findGlobal(reader); err != nil {
 				return err
 			}
+		case "7":
+			if err := showSearchScopeMenu(reader); err != nil {
+				return err
+			}
+		case "8":
+			if err := findByQuery(reader); err != nil {
+				return err
+			}
+		case "9":
+			if err := findDuplicates(reader); err != nil {
+				return err
+			}
+		case "10":
+			if err := analyzeUsage(reader); err != nil {
+				return err
+			}
+		case "11":
+			if err := findStructured(reader); err != nil {
+				return err
+			}
+		case "12":
+			if err := replaceContent(reader); err != nil {
+				return err
+			}
+		case "13":
+			if err := undoReplace(reader); err != nil {
+				return err
+			}
 		case "0":
 			return nil
 		default:
@@ -89,8 +132,10 @@ func findByName(reader *bufio.Reader) error {
 	}
 	exact := strings.TrimSpace(matchType) == "2"
 	printYellow(voice.Line("searching"))
-	results, err := finder.FindByName(path, keyword, exact)
-	if err != nil {
+	ctx, stop := searchContext()
+	results, err := finder.FindByName(ctx, path, keyword, exact)
+	stop()
+	if err := searchStopped(err); err != nil {
 		return err
 	}
 	return showFinderResults(reader, results)
@@ -107,8 +152,10 @@ func findByExt(reader *bufio.Reader) error {
 		return err
 	}
 	printYellow(voice.Line("searching"))
-	results, err := finder.FindByExt(path, strings.TrimSpace(ext))
-	if err != nil {
+	ctx, stop := searchContext()
+	results, err := finder.FindByExt(ctx, path, strings.TrimSpace(ext))
+	stop()
+	if err := searchStopped(err); err != nil {
 		return err
 	}
 	return showFinderResults(reader, results)
@@ -124,22 +171,86 @@ func findByContent(reader *bufio.Reader) error {
 	if err != nil {
 		return err
 	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		printRed(voice.Line("invalid_keyword"))
+		return waitForEnter(reader)
+	}
+	fmt.Printf("  %s", voice.Line("finder_content_flags_prompt"))
+	flags, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	opts, err := parseContentFlags(flags)
+	if err != nil {
+		printRed(err.Error())
+		return waitForEnter(reader)
+	}
 	printYellow(voice.Line("searching"))
-	results, err := finder.FindByContent(path, strings.TrimSpace(query))
+
+	var mu sync.Mutex
+	live := isTerminal()
+	var last finder.Progress
+	progress := func(p finder.Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		last = p
+		if !live {
+			return
+		}
+		if p.Done {
+			fmt.Print("\r\033[K")
+			return
+		}
+		fmt.Printf("\r\033[K  %s", truncateText(voice.Linef("searching_progress", p.Scanned, p.Files, p.Matches, p.Current), 100))
+	}
+	opts.Progress = progress
+	ctx, stop := searchContext()
+	defer stop()
+	stream, err := finder.SearchContent(ctx, path, query, opts)
 	if err != nil {
+		printRed(err.Error())
+		return waitForEnter(reader)
+	}
+	matches := make([]finder.ContentResult, 0)
+	printer := &contentPrinter{context: opts.Before > 0 || opts.After > 0}
+	for batch := range stream {
+		mu.Lock()
+		if live {
+			fmt.Print("\r\033[K")
+		}
+		if len(matches) == 0 {
+			printWhite("\n  FILE                             LINE  CONTENT")
+		}
+		for _, item := range batch {
+			printer.print(item)
+		}
+		mu.Unlock()
+		matches = append(matches, batch...)
+	}
+	stop()
+	mu.Lock()
+	stopped := last.Err
+	mu.Unlock()
+	if err := searchStopped(stopped); err != nil {
 		return err
 	}
-	if len(results) == 0 {
+	if last.Failed > 0 {
+		printYellow(voice.Linef("finder_archive_failed", last.Failed))
+	}
+	if len(matches) == 0 {
 		printYellow(voice.Line("no_results"))
 		return waitForEnter(reader)
 	}
-	printWhite("\n  FILE                             LINE  CONTENT")
-	for _, item := range results {
-		printBlue(fmt.Sprintf("  %-32s", item.Path))
-		printWhite(fmt.Sprintf("  %-4d %s", item.Line, item.Content))
+	if live {
+		finder.SortContentResults(matches)
+		return browseAndAct(reader, voice.Linef("finder_results_matches", len(matches)), contentItems(matches), func() {
+			printMagenta(voice.Linef("finder_content_summary", len(matches), last.Scanned, last.Binary))
+		})
 	}
+	printMagenta(voice.Linef("finder_content_summary", len(matches), last.Scanned, last.Binary))
 	printMagenta(voice.Line("finder_content_success"))
-	return waitForEnter(reader)
+	return showResultActions(reader, contentTargets(matches), false)
 }
 
 func findBySize(reader *bufio.Reader) error {
@@ -160,8 +271,10 @@ func findBySize(reader *bufio.Reader) error {
 		return err
 	}
 	printYellow(voice.Line("searching"))
-	results, err := finder.FindBySize(path, cond, strings.TrimSpace(sizeInput))
-	if err != nil {
+	ctx, stop := searchContext()
+	results, err := finder.FindBySize(ctx, path, cond, strings.TrimSpace(sizeInput))
+	stop()
+	if err := searchStopped(err); err != nil {
 		return err
 	}
 	return showFinderResults(reader, results)
@@ -184,8 +297,10 @@ func findByTime(reader *bufio.Reader) error {
 		return err
 	}
 	printYellow(voice.Line("searching"))
-	results, err := finder.FindByTime(path, strings.TrimSpace(cond), strings.TrimSpace(daysInput))
-	if err != nil {
+	ctx, stop := searchContext()
+	results, err := finder.FindByTime(ctx, path, strings.TrimSpace(cond), strings.TrimSpace(daysInput))
+	stop()
+	if err := searchStopped(err); err != nil {
 		return err
 	}
 	return showFinderResults(reader, results)
@@ -224,8 +339,10 @@ func findGlobal(reader *bufio.Reader) error {
 	}
 	printYellow(fmt.Sprintf("%s%s", voice.Line("searching_dir"), searchPath))
 
-	results, err := finder.FindByNameWithExt(searchPath, query, extInput, exact)
-	if err != nil {
+	ctx, stop := searchContext()
+	results, err := finder.FindByNameWithExt(ctx, searchPath, query, extInput, exact)
+	stop()
+	if err := searchStopped(err); err != nil {
 		return err
 	}
 	if len(results) == 0 {
@@ -233,14 +350,224 @@ func findGlobal(reader *bufio.Reader) error {
 		return waitForEnter(reader)
 	}
 
+	if isTerminal() {
+		return browseAndAct(reader, voice.Linef("finder_results_title", len(results)), fileItems(results), func() {
+			printMagenta(fmt.Sprintf("%s%s", voice.Line("global_found"), searchPath))
+		})
+	}
 	printMagenta(fmt.Sprintf("\n  %s", voice.Linef("finder_results_count", len(results))))
-	printWhite("\n  PATH                             SIZE    MODIFIED")
+	printFinderResults(results)
+	printMagenta(fmt.Sprintf("%s%s", voice.Line("global_found"), searchPath))
+	return showResultActions(reader, resultTargets(results), true)
+}
+
+func findByQuery(reader *bufio.Reader) error {
+	path, err := promptPath(reader)
+	if err != nil {
+		return err
+	}
+	printMagenta(voice.Line("finder_query_help"))
+	printBlue("      name:*.log size>100M mtime<7d -path:vendor content:/panic/")
+	printBlue("      (ext:yaml OR ext:yml) NOT path:test content:image")
+	printBlue("      type:d name:dist mtime>2024-01-01   (日期按先后比较，> 表示在那天之后；7d 这类时长按距今多久比较)")
+	fmt.Printf("  %s", voice.Line("finder_query_prompt"))
+	expression, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	query, err := finder.ParseQuery(strings.TrimSpace(expression))
+	if err != nil {
+		printRed(err.Error())
+		return waitForEnter(reader)
+	}
+	printYellow(voice.Line("searching"))
+	ctx, stop := searchContext()
+	results, err := finder.FindByQueryMatcher(ctx, path, query)
+	stop()
+	if err := searchStopped(err); err != nil {
+		return err
+	}
+	return showFinderResults(reader, results)
+}
+
+func findStructured(reader *bufio.Reader) error {
+	path, err := promptPath(reader)
+	if err != nil {
+		return err
+	}
+	printMagenta(voice.Line("finder_struct_help"))
+	printBlue("      spec.containers[*].image        值: *:1.2.*")
+	printBlue("      **.image                        值: registry.old.example.com")
+	printBlue("      dependencies.serde.version")
+	fmt.Printf("  %s", voice.Line("finder_struct_path_prompt"))
+	keyPath, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  %s", voice.Line("finder_struct_value_prompt"))
+	value, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	keyPath, value = strings.TrimSpace(keyPath), strings.TrimSpace(value)
+	if keyPath == "" && value == "" {
+		printRed(voice.Line("finder_struct_need_query"))
+		return waitForEnter(reader)
+	}
+	printYellow(voice.Line("searching"))
+	ctx, stop := searchContext()
+	results, err := finder.FindStructured(ctx, path, keyPath, value)
+	stop()
+	if err := searchStopped(err); err != nil {
+		printRed(err.Error())
+		return waitForEnter(reader)
+	}
+	if len(results) == 0 {
+		printYellow(voice.Line("no_results"))
+		return waitForEnter(reader)
+	}
+
+	targets := make([]actionTarget, 0)
+	seen := make(map[string]bool)
+	printWhite("\n  FILE                             LINE  PATH = VALUE")
 	for _, item := range results {
-		printFinderPath(item)
-		printWhite(fmt.Sprintf("  %-7s %s", item.Size, item.Modified))
+		if !seen[item.Path] {
+			seen[item.Path] = true
+			targets = append(targets, actionTarget{path: item.Path, line: item.Line})
+			printBlue(fmt.Sprintf("  %-32s", item.Path))
+		}
+		keyPath := item.KeyPath
+		if item.Docs > 1 {
+			keyPath = fmt.Sprintf("#%d %s", item.Doc, keyPath)
+		}
+		fmt.Printf("  %s %s = %s\n",
+			color.New(color.FgWhite).Sprintf("%-4d", item.Line),
+			color.New(color.FgCyan).Sprint(keyPath),
+			color.New(color.FgWhite).Sprint(truncateText(item.Value, 120)))
 	}
-	printMagenta(fmt.Sprintf("%s%s", voice.Line("global_found"), searchPath))
-	return waitForEnter(reader)
+	printMagenta(voice.Linef("finder_struct_summary", len(targets), len(results)))
+	return showResultActions(reader, targets, false)
+}
+
+func showSearchScopeMenu(reader *bufio.Reader) error {
+	for {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		printCyan("[搜索范围设置]")
+		printMagenta(voice.Line("finder_scope_intro"))
+		fmt.Printf("  1. 搜索隐藏目录: %s\n", onOff(cfg.SearchHidden))
+		fmt.Printf("  2. 遵循 %s: %s\n", strings.Join(finder.IgnoreFiles, " / "), onOff(cfg.RespectIgnore))
+		fmt.Printf("  3. 搜索压缩包内部: %s\n", onOff(cfg.SearchArchives))
+		fmt.Printf("  4. 使用文件索引: %s\n", onOff(cfg.UseIndex))
+		fmt.Println("  5. 重建文件索引")
+		if stats, ok, err := finder.IndexInfo(); err == nil && ok {
+			printWhite(fmt.Sprintf("     %s", voice.Linef("finder_index_info", stats.Files, stats.Dirs, stats.Built.Format("2006-01-02 15:04"))))
+		}
+		fmt.Printf("  6. 最大搜索深度: %s\n", limitText(cfg.SearchMaxDepth, "层"))
+		fmt.Printf("  7. 最多结果数: %s\n", limitText(cfg.SearchMaxResults, "条"))
+		fmt.Printf("  8. 搜索时限: %s\n", limitText(cfg.SearchTimeout, "秒"))
+		fmt.Println("  0. 返回")
+		fmt.Printf("\n  %s", voice.Line("menu_prompt"))
+
+		choice, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		switch strings.TrimSpace(choice) {
+		case "1":
+			cfg.SearchHidden = !cfg.SearchHidden
+		case "2":
+			cfg.RespectIgnore = !cfg.RespectIgnore
+		case "3":
+			cfg.SearchArchives = !cfg.SearchArchives
+		case "4":
+			cfg.UseIndex = !cfg.UseIndex
+		case "5":
+			printYellow(voice.Line("finder_index_building"))
+			start := time.Now()
+			ctx, stop := searchContext()
+			stats, err := finder.RebuildIndex(ctx)
+			stop()
+			if errors.Is(err, context.Canceled) {
+				printYellow(voice.Line("finder_search_cancelled"))
+			} else if err != nil {
+				printRed(err.Error())
+			} else {
+				printGreen(voice.Linef("finder_index_built", stats.Files, stats.Dirs, time.Since(start).Round(time.Millisecond)))
+			}
+			continue
+		case "6", "7", "8":
+			prompt := map[string]string{"6": "finder_depth_prompt", "7": "finder_max_results_prompt", "8": "finder_timeout_prompt"}[strings.TrimSpace(choice)]
+			fmt.Printf("  %s", voice.Line(prompt))
+			input, err := reader.ReadString('\n')
+			if err != nil {
+				return err
+			}
+			limit := 0
+			if input = strings.TrimSpace(input); input != "" {
+				if limit, err = strconv.Atoi(input); err != nil || limit < 0 {
+					printRed(voice.Line("finder_invalid_limit"))
+					continue
+				}
+			}
+			switch strings.TrimSpace(choice) {
+			case "6":
+				cfg.SearchMaxDepth = limit
+			case "7":
+				cfg.SearchMaxResults = limit
+			default:
+				cfg.SearchTimeout = limit
+			}
+		case "0":
+			return nil
+		default:
+			printRed(voice.Line("invalid_option"))
+			if err := waitForEnter(reader); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := config.Save(cfg); err != nil {
+			printRed(err.Error())
+		} else {
+			printGreen(voice.Line("finder_scope_saved"))
+		}
+	}
+}
+
+func searchContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+func searchStopped(err error) error {
+	switch {
+	case err == nil:
+	case errors.Is(err, context.Canceled):
+		printYellow(voice.Line("finder_search_cancelled"))
+	case errors.Is(err, context.DeadlineExceeded):
+		printYellow(voice.Line("finder_search_timeout"))
+	case errors.Is(err, finder.ErrMaxResults):
+		printYellow(voice.Line("finder_search_limit"))
+	default:
+		return err
+	}
+	return nil
+}
+
+func limitText(value int, unit string) string {
+	if value <= 0 {
+		return "不限"
+	}
+	return fmt.Sprintf("%d %s", value, unit)
+}
+
+func onOff(value bool) string {
+	if value {
+		return "开"
+	}
+	return "关"
 }
 
 func promptPath(reader *bufio.Reader) (string, error) {
@@ -265,21 +592,134 @@ func showFinderResults(reader *bufio.Reader, results []finder.Result) error {
 		printYellow(voice.Line("no_results"))
 		return waitForEnter(reader)
 	}
+	if isTerminal() {
+		return browseAndAct(reader, voice.Linef("finder_results_title", len(results)), fileItems(results), func() {
+			printMagenta(voice.Line("finder_results_done"))
+		})
+	}
 	printMagenta(fmt.Sprintf("\n  %s", voice.Linef("finder_results_count", len(results))))
-	printWhite("\n  PATH                             SIZE    MODIFIED")
-	for _, item := range results {
-		printFinderPath(item)
+	printFinderResults(results)
+	printMagenta(voice.Line("finder_results_done"))
+	return showResultActions(reader, resultTargets(results), true)
+}
+
+func browseAndAct(reader *bufio.Reader, title string, items []resultItem, summary func()) error {
+	chosen, err := browseResults(title, items)
+	if err != nil {
+		return err
+	}
+	printMagenta(fmt.Sprintf("\n  %s", title))
+	summary()
+	if len(chosen) < len(items) {
+		printMagenta(voice.Linef("finder_results_chosen", len(chosen)))
+	}
+	return showResultActions(reader, itemTargets(chosen), false)
+}
+
+func printFinderResults(results []finder.Result) {
+	printWhite("\n  #    PATH                             SIZE    MODIFIED")
+	for i, item := range results {
+		printFinderPath(i+1, item)
 		printWhite(fmt.Sprintf("  %-7s %s", item.Size, item.Modified))
 	}
-	printMagenta(voice.Line("finder_results_done"))
-	return waitForEnter(reader)
 }
 
-func printFinderPath(item finder.Result) {
-	path := fmt.Sprintf("  %-32s", item.Path)
+func printFinderPath(index int, item finder.Result) {
+	path := fmt.Sprintf("  %-4d %-32s", index, item.Path)
 	if item.IsDir {
 		color.New(color.FgYellow).Print(path)
 		return
 	}
 	printBlue(path)
 }
+
+func parseContentFlags(input string) (finder.ContentOptions, error) {
+	var opts finder.ContentOptions
+	for _, token := range strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return r == ',' || r == '，' || unicode.IsSpace(r)
+	}) {
+		if len(token) > 1 && strings.ContainsRune("abc", rune(token[0])) {
+			if lines, err := strconv.Atoi(token[1:]); err == nil && lines >= 0 {
+				switch token[0] {
+				case 'a':
+					opts.After = lines
+				case 'b':
+					opts.Before = lines
+				default:
+					opts.Before, opts.After = lines, lines
+				}
+				continue
+			}
+		}
+		for _, flag := range token {
+			switch flag {
+			case 'r':
+				opts.Regex = true
+			case 'i':
+				opts.IgnoreCase = true
+			case 'w':
+				opts.WholeWord = true
+			case 'v':
+				opts.Invert = true
+			case 'z':
+				opts.Archives = true
+			default:
+				return opts, errors.New(voice.Linef("finder_invalid_flag", string(flag)))
+			}
+		}
+	}
+	return opts, nil
+}
+
+type contentPrinter struct {
+	context bool
+	path    string
+	line    int
+}
+
+func (p *contentPrinter) print(item finder.ContentResult) {
+	first := item.Line
+	if len(item.Before) > 0 {
+		first = item.Before[0].Line
+	}
+	if item.Path != p.path {
+		printBlue(fmt.Sprintf("  %-32s", item.Path))
+		p.path, p.line = item.Path, 0
+	} else if p.context && first > p.line+1 {
+		color.New(color.FgHiBlack).Println("  --")
+	}
+	for _, context := range item.Before {
+		p.printContext(context)
+	}
+	fmt.Printf("  %s %s\n", color.New(color.FgWhite).Sprintf("%-4d", item.Line), highlightSpans(item.Content, item.Spans))
+	p.line = item.Line
+	for _, context := range item.After {
+		p.printContext(context)
+	}
+}
+
+func (p *contentPrinter) printContext(context finder.ContextLine) {
+	if context.Line <= p.line {
+		return
+	}
+	color.New(color.FgHiBlack).Printf("  %-4d %s\n", context.Line, context.Content)
+	p.line = context.Line
+}
+
+func highlightSpans(text string, spans []finder.Span) string {
+	if len(spans) == 0 {
+		return color.New(color.FgWhite).Sprint(text)
+	}
+	var out strings.Builder
+	last := 0
+	for _, span := range spans {
+		if span.Start < last || span.End > len(text) {
+			continue
+		}
+		out.WriteString(color.New(color.FgWhite).Sprint(text[last:span.Start]))
+		out.WriteString(color.New(color.FgRed, color.Bold).Sprint(text[span.Start:span.End]))
+		last = span.End
+	}
+	out.WriteString(color.New(color.FgWhite).Sprint(text[last:]))
+	return out.String()
+}
@@ -3,12 +3,11 @@ package cmd
 import (
 	"bufio"
 	"fmt"
-	"os"
-	"os/exec"
 	"strconv"
 	"strings"
 
 	"sakibox/internal/history"
+	"sakibox/internal/runlog"
 	"sakibox/internal/voice"
 )
 
@@ -19,6 +18,8 @@ func showHistoryMenu(reader *bufio.Reader) error {
 		fmt.Println("  1. 查看历史命令")
 		fmt.Println("  2. 搜索历史命令")
 		fmt.Println("  3. 执行历史命令")
+		fmt.Println("  4. 使用统计")
+		fmt.Println("  5. 敏感信息扫描")
 		fmt.Println("  0. 返回主菜单")
 		fmt.Printf("\n  %s", voice.Line("menu_prompt"))
 
@@ -82,7 +83,17 @@ func showHistoryMenu(reader *bufio.Reader) error {
 				}
 				continue
 			}
-			if err := executeShellCommand(cmdLine); err != nil {
+			cmdLine, ok, err := prepareCommand(reader, cmdLine)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				if err := waitForEnter(reader); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := executeShellCommand("history", "", cmdLine, ""); err != nil {
 				printRed(err.Error())
 			} else {
 				printMagenta(voice.Line("history_exec_success"))
@@ -90,6 +101,14 @@ func showHistoryMenu(reader *bufio.Reader) error {
 			if err := waitForEnter(reader); err != nil {
 				return err
 			}
+		case "4":
+			if err := showHistoryStats(reader); err != nil {
+				return err
+			}
+		case "5":
+			if err := scanHistorySecrets(reader); err != nil {
+				return err
+			}
 		case "0":
 			return nil
 		default:
@@ -117,10 +136,160 @@ func printHistoryList(entries []history.Entry) {
 	}
 }
 
-func executeShellCommand(command string) error {
-	cmd := exec.Command("/bin/sh", "-c", command)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+func showHistoryStats(reader *bufio.Reader) error {
+	entries, err := history.All()
+	if err != nil {
+		return err
+	}
+	runs, err := runlog.List()
+	if err != nil {
+		return err
+	}
+	for _, run := range runs {
+		entries = append(entries, history.Entry{
+			Command:     run.Command,
+			Time:        run.Start,
+			Dir:         run.Dir,
+			ExitCode:    run.ExitCode,
+			HasExitCode: true,
+		})
+	}
+	if len(entries) == 0 {
+		printYellow(voice.Line("history_stats_empty"))
+		return waitForEnter(reader)
+	}
+	stats := history.Analyze(entries, 10)
+
+	printMagenta(fmt.Sprintf("\n  %s", voice.Linef("history_stats_total", stats.Total)))
+	if !stats.First.IsZero() {
+		fmt.Printf("  %s ~ %s\n", stats.First.Format("2006-01-02"), stats.Last.Format("2006-01-02"))
+	}
+
+	printWhite("\n  TOP COMMANDS")
+	printCountBars(stats.TopCommands)
+	printWhite("\n  TOP TOOLS")
+	printCountBars(stats.TopTools)
+	if len(stats.TopDirs) > 0 {
+		printWhite("\n  TOP DIRECTORIES")
+		printCountBars(stats.TopDirs)
+	}
+	if len(stats.Weekly) > 0 {
+		printWhite("\n  WEEKLY")
+		printCountBars(stats.Weekly)
+	}
+	printWhite("\n  FAILURES")
+	if len(stats.Failures) == 0 {
+		printYellow("  " + voice.Line("history_stats_no_failures"))
+	}
+	for _, item := range stats.Failures {
+		fmt.Printf("  %3d/%-3d %s\n", item.Failures, item.Runs, truncateText(item.Command, 60))
+	}
+	if stats.Timed > 0 {
+		printWhite("\n  HEATMAP")
+		printHeatmap(stats.Heatmap)
+	} else {
+		printYellow("  " + voice.Line("history_stats_no_time"))
+	}
+	printMagenta(voice.Line("history_stats_done"))
+	return waitForEnter(reader)
+}
+
+func scanHistorySecrets(reader *bufio.Reader) error {
+	printYellow(voice.Line("history_secret_scanning"))
+	findings, err := history.ScanSecrets()
+	if err != nil {
+		return err
+	}
+	if len(findings) == 0 {
+		printGreen(voice.Line("history_secret_clean"))
+		return waitForEnter(reader)
+	}
+	printWhite("\n  FILE                             LINE   KIND               CONTENT")
+	files := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, item := range findings {
+		fmt.Printf("  %-32s %-6d %-18s %s\n", item.File, item.Line, item.Kind, truncateText(item.Preview, 60))
+		if !seen[item.File] {
+			seen[item.File] = true
+			files = append(files, item.File)
+		}
+	}
+	printMagenta(voice.Linef("history_secret_found", len(findings)))
+	fmt.Printf("  %s", voice.Line("history_secret_confirm"))
+	confirm, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
+		printYellow(voice.Line("history_secret_cancel"))
+		return waitForEnter(reader)
+	}
+	for _, path := range files {
+		backup, count, err := history.Redact(path)
+		if err != nil {
+			printRed(err.Error())
+			continue
+		}
+		printGreen(voice.Linef("history_secret_redacted", path, count, backup))
+	}
+	printYellow(voice.Line("history_secret_shell_hint"))
+	return waitForEnter(reader)
+}
+
+func printCountBars(items []history.Count) {
+	max := 0
+	for _, item := range items {
+		if item.Count > max {
+			max = item.Count
+		}
+	}
+	for _, item := range items {
+		width := 0
+		if max > 0 {
+			width = item.Count * 30 / max
+		}
+		if item.Count > 0 && width == 0 {
+			width = 1
+		}
+		fmt.Printf("  %-32s %5d ", truncateText(item.Name, 32), item.Count)
+		printCyan(strings.Repeat("█", width))
+	}
+}
+
+func printHeatmap(heatmap [7][24]int) {
+	days := []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+	shades := []string{"  ", "░░", "▒▒", "▓▓", "██"}
+	max := 0
+	for _, row := range heatmap {
+		for _, value := range row {
+			if value > max {
+				max = value
+			}
+		}
+	}
+	fmt.Print("      ")
+	for hour := 0; hour < 24; hour += 3 {
+		fmt.Printf("%-6d", hour)
+	}
+	fmt.Println()
+	for day, row := range heatmap {
+		fmt.Printf("  %s ", days[day])
+		for _, value := range row {
+			level := 0
+			if value > 0 && max > 0 {
+				level = 1 + value*(len(shades)-2)/max
+			}
+			fmt.Print(shades[level])
+		}
+		fmt.Println()
+	}
+}
+
+func truncateText(text string, width int) string {
+	text = strings.ReplaceAll(text, "\n", " ")
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+	return string(runes[:width-1]) + "…"
 }
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+func editLine(reader *bufio.Reader, prompt, initial string) (string, bool, error) {
+	if !isTerminal() {
+		fmt.Printf("%s%s\n", prompt, initial)
+		fmt.Print(prompt)
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return "", false, err
+		}
+		input = strings.TrimSpace(input)
+		if input == "" {
+			return initial, true, nil
+		}
+		return input, true, nil
+	}
+
+	buf := []rune(initial)
+	cursor := len(buf)
+	accepted := false
+	err := withRawTerminal(func() error {
+		redrawLine(prompt, buf, cursor)
+		for {
+			events, err := readKeys()
+			if err != nil {
+				return err
+			}
+			for _, event := range events {
+				switch event.kind {
+				case keyEnter:
+					accepted = true
+					return nil
+				case keyCtrlC, keyEscape:
+					return nil
+				case keyRune:
+					buf = append(buf[:cursor], append([]rune{event.r}, buf[cursor:]...)...)
+					cursor++
+				case keyTab:
+					buf = append(buf[:cursor], append([]rune{' '}, buf[cursor:]...)...)
+					cursor++
+				case keyBackspace:
+					if cursor > 0 {
+						buf = append(buf[:cursor-1], buf[cursor:]...)
+						cursor--
+					}
+				case keyDelete:
+					if cursor < len(buf) {
+						buf = append(buf[:cursor], buf[cursor+1:]...)
+					}
+				case keyLeft:
+					if cursor > 0 {
+						cursor--
+					}
+				case keyRight:
+					if cursor < len(buf) {
+						cursor++
+					}
+				case keyHome, keyCtrlA:
+					cursor = 0
+				case keyEnd, keyCtrlE:
+					cursor = len(buf)
+				case keyCtrlK:
+					buf = buf[:cursor]
+				case keyCtrlU:
+					buf = buf[cursor:]
+					cursor = 0
+				case keyCtrlW:
+					start := cursor
+					for start > 0 && unicode.IsSpace(buf[start-1]) {
+						start--
+					}
+					for start > 0 && !unicode.IsSpace(buf[start-1]) {
+						start--
+					}
+					buf = append(buf[:start], buf[cursor:]...)
+					cursor = start
+				}
+			}
+			redrawLine(prompt, buf, cursor)
+		}
+	})
+	fmt.Print("\r\n")
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimSpace(string(buf)), accepted, nil
+}
+
+func redrawLine(prompt string, buf []rune, cursor int) {
+	var line strings.Builder
+	line.WriteString("\r\033[K")
+	line.WriteString(prompt)
+	line.WriteString(string(buf))
+	if back := textWidth(buf[cursor:]); back > 0 {
+		line.WriteString(fmt.Sprintf("\033[%dD", back))
+	}
+	_, _ = os.Stdout.WriteString(line.String())
+}
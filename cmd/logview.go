@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+
+	"sakibox/internal/logtail"
+	"sakibox/internal/voice"
+)
+
+const (
+	tailBacklog  = 200
+	tailMaxLines = 10000
+	tailRefresh  = 100 * time.Millisecond
+)
+
+var tailSourceColors = []color.Attribute{color.FgCyan, color.FgMagenta, color.FgBlue, color.FgGreen}
+
+type logView struct {
+	paths   []string
+	lines   []logtail.Line
+	filter  *regexp.Regexp
+	paused  bool
+	scroll  int
+	unseen  int
+	editing bool
+	input   []rune
+	status  string
+}
+
+func followFiles(paths []string) error {
+	if !isTerminal() {
+		for _, path := range paths {
+			if len(paths) > 1 {
+				printCyan("==> " + path + " <==")
+			}
+			for _, line := range logtail.Last(path, tailBacklog) {
+				fmt.Println(line)
+			}
+		}
+		return nil
+	}
+	return withRawTerminal(func() error {
+		fd := int(os.Stdin.Fd())
+		if err := syscall.SetNonblock(fd, true); err != nil {
+			return err
+		}
+		defer func() { _ = syscall.SetNonblock(fd, false) }()
+		fmt.Print("\033[?1049h\033[?25l")
+		defer fmt.Print("\033[?25h\033[?1049l")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream := logtail.Follow(ctx, paths, tailBacklog)
+		view := &logView{paths: paths, lines: make([]logtail.Line, 0)}
+		ticker := time.NewTicker(tailRefresh)
+		defer ticker.Stop()
+		dirty := true
+		for {
+			select {
+			case line := <-stream:
+				view.add(line)
+				dirty = true
+				continue
+			case <-ticker.C:
+			}
+			events, err := pollKeys()
+			if err != nil {
+				return err
+			}
+			for _, event := range events {
+				if !view.key(event) {
+					return nil
+				}
+				dirty = true
+			}
+			if dirty {
+				view.render()
+				dirty = false
+			}
+		}
+	})
+}
+
+func pollKeys() ([]keyEvent, error) {
+	keys, err := readKeys()
+	if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EWOULDBLOCK) {
+		return nil, nil
+	}
+	return keys, err
+}
+
+func writeNonblocking(data []byte) {
+	for len(data) > 0 {
+		n, err := os.Stdout.Write(data)
+		data = data[n:]
+		if errors.Is(err, syscall.EAGAIN) {
+			time.Sleep(5 * time.Millisecond)
+			continue
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (v *logView) add(line logtail.Line) {
+	v.lines = append(v.lines, line)
+	if len(v.lines) > tailMaxLines {
+		v.lines = v.lines[len(v.lines)-tailMaxLines:]
+	}
+	if v.visible(line) && (v.paused || v.scroll > 0) {
+		v.scroll++
+		v.unseen++
+	}
+}
+
+func (v *logView) visible(line logtail.Line) bool {
+	return v.filter == nil || v.filter.MatchString(line.Text)
+}
+
+func (v *logView) key(event keyEvent) bool {
+	if v.editing {
+		switch event.kind {
+		case keyEnter:
+			v.editing = false
+			v.setFilter(string(v.input))
+		case keyEscape, keyCtrlC:
+			v.editing = false
+		case keyBackspace:
+			if len(v.input) > 0 {
+				v.input = v.input[:len(v.input)-1]
+			}
+		case keyCtrlU:
+			v.input = v.input[:0]
+		case keyRune:
+			v.input = append(v.input, event.r)
+		}
+		return true
+	}
+	v.status = ""
+	rows := v.rows()
+	switch {
+	case event.kind == keyCtrlC || event.kind == keyEscape || event.kind == keyRune && event.r == 'q':
+		return false
+	case event.kind == keyRune && event.r == '/':
+		v.editing = true
+		if v.filter != nil {
+			v.input = []rune(v.filter.String())
+		} else {
+			v.input = v.input[:0]
+		}
+	case event.kind == keyRune && (event.r == ' ' || event.r == 'p'):
+		v.paused = !v.paused
+		if !v.paused {
+			v.scroll, v.unseen = 0, 0
+		}
+	case event.kind == keyEnd || event.kind == keyRune && (event.r == 'G' || event.r == 'e'):
+		v.paused, v.scroll, v.unseen = false, 0, 0
+	case event.kind == keyUp || event.kind == keyRune && event.r == 'k':
+		v.scroll++
+	case event.kind == keyDown || event.kind == keyRune && event.r == 'j':
+		v.scroll--
+	case event.kind == keyPageUp:
+		v.scroll += rows
+	case event.kind == keyPageDown:
+		v.scroll -= rows
+	case event.kind == keyHome || event.kind == keyRune && event.r == 'g':
+		v.scroll = len(v.lines)
+	case event.kind == keyRune && event.r == 'c':
+		v.lines, v.scroll, v.unseen = v.lines[:0], 0, 0
+	}
+	if v.scroll <= 0 {
+		v.scroll = 0
+		if !v.paused {
+			v.unseen = 0
+		}
+	}
+	return true
+}
+
+func (v *logView) setFilter(pattern string) {
+	if pattern == "" {
+		v.filter = nil
+		v.scroll = 0
+		return
+	}
+	re, err := regexp.Compile("(?i)" + strings.TrimPrefix(pattern, "(?i)"))
+	if err != nil {
+		v.status = color.New(color.FgRed).Sprint(voice.Linef("tail_bad_filter", err))
+		return
+	}
+	v.filter, v.scroll = re, 0
+}
+
+func (v *logView) rows() int {
+	_, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || height < 8 {
+		height = 24
+	}
+	return height - 3
+}
+
+func (v *logView) render() {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width < 40 {
+		width = 80
+	}
+	rows := v.rows()
+	shown := make([]logtail.Line, 0, len(v.lines))
+	for _, line := range v.lines {
+		if v.visible(line) {
+			shown = append(shown, line)
+		}
+	}
+	v.scroll = min(v.scroll, max(len(shown)-rows, 0))
+	end := len(shown) - v.scroll
+	start := max(end-rows, 0)
+
+	var out strings.Builder
+	out.WriteString("\033[H\033[2J")
+	out.WriteString(color.New(color.FgCyan).Sprint("[日志跟踪] "))
+	title := strings.Join(v.paths, ", ")
+	if len(v.paths) > 1 {
+		title = voice.Linef("tail_files", len(v.paths))
+	}
+	out.WriteString(truncateText(title, width-30))
+	switch {
+	case v.paused:
+		out.WriteString(color.New(color.FgYellow).Sprint("  " + voice.Linef("tail_paused", v.unseen)))
+	case v.scroll > 0:
+		out.WriteString(color.New(color.FgYellow).Sprint("  " + voice.Linef("tail_scrolled", v.unseen)))
+	}
+	if v.filter != nil {
+		out.WriteString(color.New(color.FgMagenta).Sprint("  /" + v.filter.String()))
+	}
+	out.WriteString("\r\n")
+	for _, line := range shown[start:end] {
+		out.WriteString(v.format(line, width))
+		out.WriteString("\r\n")
+	}
+	for i := end - start; i < rows; i++ {
+		out.WriteString("\r\n")
+	}
+	switch {
+	case v.editing:
+		out.WriteString("  / " + string(v.input) + "\033[7m \033[0m")
+	case v.status != "":
+		out.WriteString("  " + v.status)
+	default:
+		out.WriteString(color.New(color.FgHiBlack).Sprint("  / 过滤  空格 暂停/继续  ↑↓ PgUp/PgDn 滚动  G 跳到末尾  c 清屏  q 退出"))
+	}
+	writeNonblocking([]byte(out.String()))
+}
+
+func (v *logView) format(line logtail.Line, width int) string {
+	prefix, prefixWidth := "", 0
+	if len(v.paths) > 1 {
+		prefixWidth = 13
+		index := 0
+		for i, path := range v.paths {
+			if path == line.Path {
+				index = i
+			}
+		}
+		prefix = color.New(tailSourceColors[index%len(tailSourceColors)]).Sprintf("%-12s ", truncateText(filepath.Base(line.Path), 12))
+	}
+	text := truncateText(strings.ReplaceAll(line.Text, "\t", "    "), max(width-prefixWidth-1, 10))
+	base := color.New(color.FgWhite)
+	switch logtail.LevelOf(line.Text) {
+	case logtail.LevelError:
+		base = color.New(color.FgRed)
+	case logtail.LevelWarn:
+		base = color.New(color.FgYellow)
+	case logtail.LevelDebug:
+		base = color.New(color.FgHiBlack)
+	}
+	if v.filter == nil {
+		return prefix + base.Sprint(text)
+	}
+	var out strings.Builder
+	last := 0
+	for _, loc := range v.filter.FindAllStringIndex(text, -1) {
+		if loc[1] == loc[0] {
+			continue
+		}
+		out.WriteString(base.Sprint(text[last:loc[0]]))
+		out.WriteString(color.New(color.Bold, color.ReverseVideo).Sprint(text[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	out.WriteString(base.Sprint(text[last:]))
+	return prefix + out.String()
+}
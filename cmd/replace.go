@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"sakibox/internal/finder"
+	"sakibox/internal/voice"
+)
+
+func replaceContent(reader *bufio.Reader) error {
+	path, err := promptPath(reader)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  %s", voice.Line("finder_content_prompt"))
+	query, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		printRed(voice.Line("invalid_keyword"))
+		return waitForEnter(reader)
+	}
+	fmt.Printf("  %s", voice.Line("finder_replace_prompt"))
+	replacement, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	replacement = strings.TrimRight(replacement, "\r\n")
+	fmt.Printf("  %s", voice.Line("finder_replace_flags_prompt"))
+	flags, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	opts, err := parseContentFlags(flags)
+	if err != nil {
+		printRed(err.Error())
+		return waitForEnter(reader)
+	}
+	printYellow(voice.Line("searching"))
+	ctx, stop := searchContext()
+	changes, err := finder.PlanReplace(ctx, path, query, replacement, opts)
+	stop()
+	if err != nil {
+		if searchStopped(err) == nil {
+			printYellow(voice.Line("finder_replace_incomplete"))
+		} else {
+			printRed(err.Error())
+		}
+		return waitForEnter(reader)
+	}
+	if len(changes) == 0 {
+		printYellow(voice.Line("no_results"))
+		return waitForEnter(reader)
+	}
+
+	hunks, lines := 0, 0
+	for _, change := range changes {
+		printFileHeader(change.Path)
+		for _, hunk := range change.Hunks {
+			printHunk(hunk)
+			hunks++
+			lines += hunk.Changed
+		}
+	}
+	printMagenta(voice.Linef("finder_replace_summary", len(changes), hunks, lines))
+
+	fmt.Println("\n  a 全部应用  f 逐文件确认  h 逐块确认")
+	fmt.Printf("  %s", voice.Line("finder_actions_prompt"))
+	choice, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(strings.TrimSpace(choice)) {
+	case "a":
+	case "f":
+		if err := reviewFiles(reader, changes); err != nil {
+			return err
+		}
+	case "h":
+		if err := reviewHunks(reader, changes); err != nil {
+			return err
+		}
+	default:
+		printYellow(voice.Line("finder_action_cancel"))
+		return waitForEnter(reader)
+	}
+
+	accepted := 0
+	for _, change := range changes {
+		accepted += change.Accepted()
+	}
+	if accepted == 0 {
+		printYellow(voice.Line("finder_action_cancel"))
+		return waitForEnter(reader)
+	}
+	batch, err := finder.ApplyReplace(query, replacement, changes)
+	if err != nil {
+		printRed(err.Error())
+	}
+	if len(batch.Files) > 0 {
+		printGreen(voice.Linef("finder_replace_done", len(batch.Files)))
+	}
+	return waitForEnter(reader)
+}
+
+func reviewFiles(reader *bufio.Reader, changes []finder.FileChange) error {
+	for i := range changes {
+		change := &changes[i]
+		printFileHeader(change.Path)
+		for _, hunk := range change.Hunks {
+			printHunk(hunk)
+		}
+		answer, err := promptReview(reader, "finder_replace_file_prompt", i+1, len(changes))
+		if err != nil {
+			return err
+		}
+		if answer == "q" {
+			for j := i; j < len(changes); j++ {
+				setAccepted(&changes[j], false)
+			}
+			return nil
+		}
+		setAccepted(change, answer == "y")
+	}
+	return nil
+}
+
+func reviewHunks(reader *bufio.Reader, changes []finder.FileChange) error {
+	total, index := 0, 0
+	for _, change := range changes {
+		total += len(change.Hunks)
+	}
+	for i := range changes {
+		for j := range changes[i].Hunks {
+			hunk := &changes[i].Hunks[j]
+			index++
+			printFileHeader(changes[i].Path)
+			printHunk(*hunk)
+			answer, err := promptReview(reader, "finder_replace_hunk_prompt", index, total)
+			if err != nil {
+				return err
+			}
+			if answer == "q" {
+				for k := j; k < len(changes[i].Hunks); k++ {
+					changes[i].Hunks[k].Accepted = false
+				}
+				for k := i + 1; k < len(changes); k++ {
+					setAccepted(&changes[k], false)
+				}
+				return nil
+			}
+			hunk.Accepted = answer == "y"
+		}
+	}
+	return nil
+}
+
+func promptReview(reader *bufio.Reader, key string, index, total int) (string, error) {
+	fmt.Printf("  %s", voice.Linef(key, index, total))
+	answer, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(strings.TrimSpace(answer)), nil
+}
+
+func setAccepted(change *finder.FileChange, accepted bool) {
+	for i := range change.Hunks {
+		change.Hunks[i].Accepted = accepted
+	}
+}
+
+func printFileHeader(path string) {
+	color.New(color.Bold).Printf("\n  --- %s\n  +++ %s\n", path, path)
+}
+
+func printHunk(hunk finder.ReplaceHunk) {
+	for _, line := range strings.Split(hunk.Diff(), "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			color.New(color.FgCyan).Println("  " + line)
+		case strings.HasPrefix(line, "-"):
+			color.New(color.FgRed).Println("  " + line)
+		case strings.HasPrefix(line, "+"):
+			color.New(color.FgGreen).Println("  " + line)
+		default:
+			color.New(color.FgHiBlack).Println("  " + line)
+		}
+	}
+}
+
+func undoReplace(reader *bufio.Reader) error {
+	batch, ok, err := finder.LastReplace()
+	if err != nil {
+		printRed(err.Error())
+		return waitForEnter(reader)
+	}
+	if !ok {
+		printYellow(voice.Line("finder_replace_nothing"))
+		return waitForEnter(reader)
+	}
+	printYellow(voice.Linef("finder_replace_last", batch.Time.Format("2006-01-02 15:04"), batch.Query, batch.Replacement, len(batch.Files)))
+	for _, entry := range batch.Files {
+		printBlue("      " + entry.Path)
+	}
+	fmt.Printf("  %s", voice.Line("finder_action_confirm"))
+	confirm, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
+		printYellow(voice.Line("finder_action_cancel"))
+		return waitForEnter(reader)
+	}
+	restored, err := finder.UndoReplace()
+	if len(restored.Files) > 0 {
+		printGreen(voice.Linef("finder_replace_undone", len(restored.Files)))
+	}
+	if err != nil {
+		printRed(err.Error())
+	}
+	return waitForEnter(reader)
+}
@@ -0,0 +1,348 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+
+	"sakibox/internal/finder"
+	"sakibox/internal/voice"
+)
+
+type resultItem struct {
+	path    string
+	line    int
+	content string
+	spans   []finder.Span
+	size    int64
+	modTime time.Time
+	isDir   bool
+}
+
+type resultSort int
+
+const (
+	sortByPath resultSort = iota
+	sortBySize
+	sortByTime
+)
+
+var resultSortNames = []string{"路径", "大小", "修改时间"}
+
+type resultsView struct {
+	title    string
+	items    []resultItem
+	marked   map[int]bool
+	order    []int
+	sort     resultSort
+	reverse  bool
+	cursor   int
+	offset   int
+	previews map[int][]finder.PreviewLine
+	failures map[int]string
+}
+
+func fileItems(results []finder.Result) []resultItem {
+	items := make([]resultItem, 0, len(results))
+	for _, result := range results {
+		items = append(items, resultItem{path: result.Path, size: result.Bytes, modTime: result.ModTime, isDir: result.IsDir})
+	}
+	return items
+}
+
+func contentItems(results []finder.ContentResult) []resultItem {
+	items := make([]resultItem, 0, len(results))
+	infos := make(map[string]os.FileInfo)
+	for _, result := range results {
+		archive, _, _ := finder.SplitArchivePath(result.Path)
+		info, ok := infos[archive]
+		if !ok {
+			info, _ = os.Stat(archive)
+			infos[archive] = info
+		}
+		item := resultItem{path: result.Path, line: result.Line, content: result.Content, spans: result.Spans}
+		if info != nil {
+			item.size, item.modTime = info.Size(), info.ModTime()
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func itemTargets(items []resultItem) []actionTarget {
+	targets := make([]actionTarget, 0, len(items))
+	seen := make(map[string]bool)
+	for _, item := range items {
+		path, _, inArchive := finder.SplitArchivePath(item.path)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		target := actionTarget{path: path, line: item.line}
+		if inArchive {
+			target.line = 0
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+func browseResults(title string, items []resultItem) ([]resultItem, error) {
+	view := &resultsView{
+		title:    title,
+		items:    items,
+		marked:   make(map[int]bool),
+		previews: make(map[int][]finder.PreviewLine),
+		failures: make(map[int]string),
+	}
+	view.resort()
+	err := withRawTerminal(func() error {
+		fmt.Print("\033[?1049h\033[?25l")
+		defer fmt.Print("\033[?25h\033[?1049l")
+		for {
+			view.render()
+			events, err := readKeys()
+			if err != nil {
+				return err
+			}
+			for _, event := range events {
+				if !view.key(event) {
+					return nil
+				}
+			}
+		}
+	})
+	if err != nil || len(view.marked) == 0 {
+		return items, err
+	}
+	chosen := make([]resultItem, 0, len(view.marked))
+	for _, index := range view.order {
+		if view.marked[index] {
+			chosen = append(chosen, items[index])
+		}
+	}
+	return chosen, nil
+}
+
+func (v *resultsView) key(event keyEvent) bool {
+	last := max(len(v.order)-1, 0)
+	rows := v.rows()
+	switch {
+	case event.kind == keyCtrlC || event.kind == keyEscape || event.kind == keyEnter || event.kind == keyRune && event.r == 'q':
+		return false
+	case event.kind == keyUp || event.kind == keyRune && event.r == 'k':
+		v.cursor = max(v.cursor-1, 0)
+	case event.kind == keyDown || event.kind == keyRune && event.r == 'j':
+		v.cursor = min(v.cursor+1, last)
+	case event.kind == keyPageUp || event.kind == keyLeft:
+		v.cursor = max(v.cursor-rows, 0)
+	case event.kind == keyPageDown || event.kind == keyRight:
+		v.cursor = min(v.cursor+rows, last)
+	case event.kind == keyHome || event.kind == keyRune && event.r == 'g':
+		v.cursor = 0
+	case event.kind == keyEnd || event.kind == keyRune && event.r == 'G':
+		v.cursor = last
+	case event.kind == keyRune && event.r == ' ':
+		if len(v.order) > 0 {
+			index := v.order[v.cursor]
+			if v.marked[index] {
+				delete(v.marked, index)
+			} else {
+				v.marked[index] = true
+			}
+			v.cursor = min(v.cursor+1, last)
+		}
+	case event.kind == keyRune && event.r == 'a':
+		if len(v.marked) > 0 {
+			v.marked = make(map[int]bool)
+		} else {
+			for _, index := range v.order {
+				v.marked[index] = true
+			}
+		}
+	case event.kind == keyRune && event.r == 's':
+		v.sort = (v.sort + 1) % resultSort(len(resultSortNames))
+		v.resort()
+	case event.kind == keyRune && event.r == 'r':
+		v.reverse = !v.reverse
+		v.resort()
+	}
+	return true
+}
+
+func (v *resultsView) resort() {
+	current := -1
+	if v.cursor < len(v.order) {
+		current = v.order[v.cursor]
+	}
+	v.order = make([]int, len(v.items))
+	for i := range v.order {
+		v.order[i] = i
+	}
+	items := v.items
+	sort.SliceStable(v.order, func(i, j int) bool {
+		a, b := items[v.order[i]], items[v.order[j]]
+		var less bool
+		switch {
+		case v.sort == sortBySize && a.size != b.size:
+			less = a.size > b.size
+		case v.sort == sortByTime && !a.modTime.Equal(b.modTime):
+			less = a.modTime.After(b.modTime)
+		case a.path != b.path:
+			less = a.path < b.path
+		default:
+			less = a.line < b.line
+		}
+		return less != v.reverse
+	})
+	for i, index := range v.order {
+		if index == current {
+			v.cursor = i
+		}
+	}
+}
+
+func (v *resultsView) rows() int {
+	_, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || height < 10 {
+		height = 24
+	}
+	return height - 4
+}
+
+func (v *resultsView) preview(index, limit int) ([]finder.PreviewLine, string) {
+	if lines, ok := v.previews[index]; ok {
+		return lines, v.failures[index]
+	}
+	item := v.items[index]
+	lines, err := finder.Preview(item.path, item.line, limit)
+	v.previews[index] = lines
+	if err != nil {
+		v.failures[index] = err.Error()
+	}
+	return lines, v.failures[index]
+}
+
+func (v *resultsView) render() {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width < 60 {
+		width = 80
+	}
+	rows := v.rows()
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	if v.cursor >= v.offset+rows {
+		v.offset = v.cursor - rows + 1
+	}
+	listWidth := width * 11 / 20
+	previewWidth := width - listWidth - 3
+
+	var out strings.Builder
+	out.WriteString("\033[H\033[2J")
+	out.WriteString(color.New(color.FgCyan).Sprint("[查找结果] "))
+	order := resultSortNames[v.sort]
+	if v.reverse {
+		order += " ↑"
+	}
+	out.WriteString(v.title)
+	out.WriteString(color.New(color.FgHiBlack).Sprintf("  %d/%d  %s", min(v.cursor+1, len(v.order)), len(v.order), voice.Linef("finder_results_sorted", order)))
+	if len(v.marked) > 0 {
+		out.WriteString(color.New(color.FgYellow).Sprint("  " + voice.Linef("finder_results_marked", len(v.marked))))
+	}
+	out.WriteString("\r\n\r\n")
+
+	var lines []finder.PreviewLine
+	var failure string
+	var selected resultItem
+	if len(v.order) > 0 {
+		selected = v.items[v.order[v.cursor]]
+		lines, failure = v.preview(v.order[v.cursor], rows)
+	}
+	for row := 0; row < rows; row++ {
+		i := v.offset + row
+		if i < len(v.order) {
+			cell := v.listCell(v.items[v.order[i]], v.marked[v.order[i]], listWidth)
+			if i == v.cursor {
+				cell = "\033[7m" + cell + "\033[0m"
+			}
+			out.WriteString(cell)
+		} else {
+			out.WriteString(strings.Repeat(" ", listWidth))
+		}
+		out.WriteString(color.New(color.FgHiBlack).Sprint(" │ "))
+		switch {
+		case failure != "" && row == 0:
+			out.WriteString(color.New(color.FgYellow).Sprint(fitText(failure, previewWidth)))
+		case row < len(lines):
+			out.WriteString(previewCell(lines[row], selected.line, previewWidth))
+		}
+		out.WriteString("\r\n")
+	}
+	out.WriteString(color.New(color.FgHiBlack).Sprint("\r\n  ↑↓ 选择  PgUp/PgDn 翻页  空格 标记  a 全选/清除  s 切换排序  r 反向  回车/q 完成"))
+	_, _ = os.Stdout.WriteString(out.String())
+}
+
+func (v *resultsView) listCell(item resultItem, marked bool, width int) string {
+	mark := "  "
+	if marked {
+		mark = "* "
+	}
+	if item.line > 0 {
+		location := fmt.Sprintf("%s:%d", item.path, item.line)
+		pathWidth := min(textWidth([]rune(location)), width*3/5)
+		return fitText(mark+tailText(location, pathWidth)+"  "+strings.TrimSpace(item.content), width)
+	}
+	info := fmt.Sprintf(" %8s %s", finder.FormatSize(item.size), item.modTime.Format("06-01-02 15:04"))
+	path := item.path
+	if item.isDir {
+		path += "/"
+		info = fmt.Sprintf(" %8s %s", "-", item.modTime.Format("06-01-02 15:04"))
+	}
+	pathWidth := max(width-len(mark)-len(info), 10)
+	return fitText(mark+fitText(tailText(path, pathWidth), pathWidth)+info, width)
+}
+
+func previewCell(line finder.PreviewLine, match, width int) string {
+	if line.Line == 0 {
+		return fitText(line.Text, width)
+	}
+	text := fitText(fmt.Sprintf("%4d %s", line.Line, line.Text), width)
+	if line.Line == match {
+		return color.New(color.FgYellow, color.Bold).Sprint(text)
+	}
+	return text
+}
+
+func fitText(text string, width int) string {
+	var out strings.Builder
+	used := 0
+	for _, r := range strings.ReplaceAll(text, "\t", "    ") {
+		if r < 0x20 || r == 0x7f {
+			r = '?'
+		}
+		if used+runeWidth(r) > width {
+			break
+		}
+		out.WriteRune(r)
+		used += runeWidth(r)
+	}
+	out.WriteString(strings.Repeat(" ", max(width-used, 0)))
+	return out.String()
+}
+
+func tailText(text string, width int) string {
+	runes := []rune(text)
+	if textWidth(runes) <= width {
+		return text
+	}
+	for len(runes) > 0 && textWidth(runes)+1 > width {
+		runes = runes[1:]
+	}
+	return "…" + string(runes)
+}
@@ -21,7 +21,12 @@ var rootCmd = &cobra.Command{
 		if err := config.EnsureConfig(); err != nil {
 			return err
 		}
-		return showMainMenu()
+		autoSync()
+		if err := showMainMenu(); err != nil {
+			return err
+		}
+		autoSync()
+		return nil
 	},
 }
 
@@ -47,6 +52,7 @@ func showMainMenu() error {
 		fmt.Println("  6. 安装帮助")
 		fmt.Println("  7. SSH 工具")
 		fmt.Println("  8. 更新 sakibox")
+		fmt.Println("  9. 运行记录")
 		fmt.Println("  0. 退出")
 		fmt.Printf("\n  %s", voice.Line("main_prompt"))
 
@@ -95,6 +101,10 @@ func showMainMenu() error {
 			if err := waitForEnter(reader); err != nil {
 				return err
 			}
+		case "9":
+			if err := showRunsMenu(reader); err != nil {
+				return err
+			}
 		case "0":
 			printMagenta(voice.Line("exit"))
 			return nil
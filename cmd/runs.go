@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"sakibox/internal/fileops"
+	"sakibox/internal/runlog"
+	"sakibox/internal/voice"
+)
+
+const runListLimit = 30
+
+func showRunsMenu(reader *bufio.Reader) error {
+	for {
+		runs, err := runlog.Recent()
+		if err != nil {
+			return err
+		}
+		printCyan("[运行记录]")
+		if len(runs) == 0 {
+			printYellow(voice.Line("run_empty"))
+			return waitForEnter(reader)
+		}
+		printMagenta(voice.Line("run_intro"))
+		printWhite("\n  #   TIME                 DURATION  EXIT  SOURCE    COMMAND")
+		for i, run := range runs {
+			if i >= runListLimit {
+				break
+			}
+			label := run.Command
+			if run.Name != "" {
+				label = fmt.Sprintf("[%s] %s", run.Name, run.Command)
+			}
+			line := fmt.Sprintf("  %-3d %-20s %-9s %-5d %-9s %s", i+1, run.Start.Format("2006-01-02 15:04:05"), runlog.FormatDuration(run.Duration()), run.ExitCode, run.Source, truncateText(label, 50))
+			if run.ExitCode != 0 {
+				printRed(line)
+			} else {
+				fmt.Println(line)
+			}
+		}
+		fmt.Printf("\n  %s", voice.Line("run_select_prompt"))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		input = strings.TrimSpace(input)
+		if input == "" || input == "0" {
+			return nil
+		}
+		index, err := strconv.Atoi(input)
+		if err != nil {
+			printRed(voice.Line("invalid_index"))
+			if err := waitForEnter(reader); err != nil {
+				return err
+			}
+			continue
+		}
+		run, err := runlog.Get(index)
+		if err != nil {
+			printRed(err.Error())
+			if err := waitForEnter(reader); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := showRunDetail(reader, run); err != nil {
+			return err
+		}
+	}
+}
+
+func showRunDetail(reader *bufio.Reader, run runlog.Run) error {
+	printWhite("\n  COMMAND   " + run.Command)
+	fmt.Printf("  SOURCE    %s %s\n", run.Source, run.Name)
+	fmt.Printf("  DIR       %s\n", run.Dir)
+	fmt.Printf("  START     %s\n", run.Start.Format("2006-01-02 15:04:05"))
+	fmt.Printf("  DURATION  %s\n", runlog.FormatDuration(run.Duration()))
+	fmt.Printf("  EXIT      %d\n", run.ExitCode)
+	if run.Error != "" {
+		printRed("  ERROR     " + run.Error)
+	}
+	if run.OutputPath == "" {
+		printYellow(voice.Line("run_no_output"))
+		return waitForEnter(reader)
+	}
+	if pager := findPager(); pager != "" && isTerminal() {
+		cmd := exec.Command("/bin/sh", "-c", pager+" "+fileops.ShellQuote(run.OutputPath))
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			printRed(err.Error())
+		}
+		return nil
+	}
+	output, err := runlog.ReadOutput(run)
+	if err != nil {
+		printRed(err.Error())
+		return waitForEnter(reader)
+	}
+	printWhite("\n  OUTPUT")
+	fmt.Println(output)
+	return waitForEnter(reader)
+}
+
+func findPager() string {
+	if pager := strings.TrimSpace(os.Getenv("PAGER")); pager != "" {
+		return pager
+	}
+	if _, err := exec.LookPath("less"); err == nil {
+		return "less -R"
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sakibox/config"
+	"sakibox/internal/gitsync"
+	"sakibox/internal/voice"
+)
+
+const autoSyncTimeout = 10 * time.Second
+
+var (
+	syncRemote string
+	syncBranch string
+)
+
+var syncCmd = &cobra.Command{
+	Use:          "sync",
+	SilenceUsage: true,
+	Short:        "sync bookmarks, quick commands and config through a git remote",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.EnsureConfig(); err != nil {
+			return err
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if syncRemote != "" || syncBranch != "" {
+			if syncRemote != "" {
+				cfg.SyncRemote = syncRemote
+			}
+			if syncBranch != "" {
+				cfg.SyncBranch = syncBranch
+			}
+			if err := config.Save(cfg); err != nil {
+				return err
+			}
+		}
+		printMagenta(voice.Linef("sync_intro", cfg.SyncRemote, strings.Join(gitsync.Files(), ", ")))
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		result, err := gitsync.Run(ctx, cfg.SyncRemote, cfg.SyncBranch)
+		if err != nil {
+			return err
+		}
+		printSyncResult(result, true)
+		return nil
+	},
+}
+
+func init() {
+	syncCmd.Flags().StringVar(&syncRemote, "remote", "", "git remote to sync with (saved as sync_remote)")
+	syncCmd.Flags().StringVar(&syncBranch, "branch", "", "remote branch (saved as sync_branch)")
+	rootCmd.AddCommand(syncCmd)
+}
+
+func autoSync() {
+	cfg, err := config.Load()
+	if err != nil || cfg.SyncRemote == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), autoSyncTimeout)
+	defer cancel()
+	result, err := gitsync.Run(ctx, cfg.SyncRemote, cfg.SyncBranch)
+	if errors.Is(err, context.DeadlineExceeded) {
+		printYellow(voice.Linef("sync_timeout", autoSyncTimeout))
+		return
+	}
+	if err != nil {
+		printYellow(voice.Linef("sync_failed", err))
+		return
+	}
+	printSyncResult(result, false)
+}
+
+func printSyncResult(result gitsync.Result, verbose bool) {
+	if len(result.Updated) > 0 {
+		printGreen(voice.Linef("sync_updated", strings.Join(result.Updated, ", ")))
+	}
+	for _, conflict := range result.Conflicts {
+		printYellow(fmt.Sprintf("  %s %s", voice.Line("sync_conflict"), conflict))
+	}
+	if result.Pushed {
+		printGreen(voice.Line("sync_pushed"))
+	}
+	if verbose && len(result.Updated) == 0 && !result.Pushed {
+		printGreen(voice.Line("sync_up_to_date"))
+	}
+}
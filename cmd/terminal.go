@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"os"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+type keyKind int
+
+const (
+	keyRune keyKind = iota
+	keyEnter
+	keyBackspace
+	keyDelete
+	keyLeft
+	keyRight
+	keyUp
+	keyDown
+	keyHome
+	keyEnd
+	keyPageUp
+	keyPageDown
+	keyEscape
+	keyTab
+	keyCtrlA
+	keyCtrlC
+	keyCtrlE
+	keyCtrlK
+	keyCtrlU
+	keyCtrlW
+)
+
+type keyEvent struct {
+	kind keyKind
+	r    rune
+}
+
+func isTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+func withRawTerminal(fn func() error) error {
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(os.Stdin.Fd()), state)
+	return fn()
+}
+
+func readKeys() ([]keyEvent, error) {
+	buf := make([]byte, 64)
+	n, err := os.Stdin.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return decodeKeys(buf[:n]), nil
+}
+
+func decodeKeys(data []byte) []keyEvent {
+	events := make([]keyEvent, 0, len(data))
+	for len(data) > 0 {
+		if data[0] == 0x1b {
+			event, size := decodeEscape(data)
+			events = append(events, event)
+			data = data[size:]
+			continue
+		}
+		switch data[0] {
+		case '\r', '\n':
+			events = append(events, keyEvent{kind: keyEnter})
+		case 127, 8:
+			events = append(events, keyEvent{kind: keyBackspace})
+		case '\t':
+			events = append(events, keyEvent{kind: keyTab})
+		case 1:
+			events = append(events, keyEvent{kind: keyCtrlA})
+		case 3:
+			events = append(events, keyEvent{kind: keyCtrlC})
+		case 5:
+			events = append(events, keyEvent{kind: keyCtrlE})
+		case 11:
+			events = append(events, keyEvent{kind: keyCtrlK})
+		case 21:
+			events = append(events, keyEvent{kind: keyCtrlU})
+		case 23:
+			events = append(events, keyEvent{kind: keyCtrlW})
+		default:
+			if data[0] < 0x20 {
+				break
+			}
+			r, size := utf8.DecodeRune(data)
+			if r != utf8.RuneError || size > 1 {
+				events = append(events, keyEvent{kind: keyRune, r: r})
+			}
+			data = data[size:]
+			continue
+		}
+		data = data[1:]
+	}
+	return events
+}
+
+func decodeEscape(data []byte) (keyEvent, int) {
+	if len(data) < 3 || (data[1] != '[' && data[1] != 'O') {
+		return keyEvent{kind: keyEscape}, 1
+	}
+	switch data[2] {
+	case 'A':
+		return keyEvent{kind: keyUp}, 3
+	case 'B':
+		return keyEvent{kind: keyDown}, 3
+	case 'C':
+		return keyEvent{kind: keyRight}, 3
+	case 'D':
+		return keyEvent{kind: keyLeft}, 3
+	case 'H':
+		return keyEvent{kind: keyHome}, 3
+	case 'F':
+		return keyEvent{kind: keyEnd}, 3
+	}
+	end := 2
+	for end < len(data) && data[end] >= '0' && data[end] <= '9' {
+		end++
+	}
+	if end >= len(data) || data[end] != '~' {
+		return keyEvent{kind: keyEscape}, 1
+	}
+	size := end + 1
+	switch string(data[2:end]) {
+	case "1", "7":
+		return keyEvent{kind: keyHome}, size
+	case "3":
+		return keyEvent{kind: keyDelete}, size
+	case "4", "8":
+		return keyEvent{kind: keyEnd}, size
+	case "5":
+		return keyEvent{kind: keyPageUp}, size
+	case "6":
+		return keyEvent{kind: keyPageDown}, size
+	}
+	return keyEvent{kind: keyEscape}, size
+}
+
+func runeWidth(r rune) int {
+	switch {
+	case r >= 0x1100 && r <= 0x115f,
+		r >= 0x2e80 && r <= 0xa4cf,
+		r >= 0xac00 && r <= 0xd7a3,
+		r >= 0xf900 && r <= 0xfaff,
+		r >= 0xfe30 && r <= 0xfe4f,
+		r >= 0xff00 && r <= 0xff60,
+		r >= 0xffe0 && r <= 0xffe6,
+		r >= 0x1f300 && r <= 0x1faff:
+		return 2
+	}
+	return 1
+}
+
+func textWidth(runes []rune) int {
+	width := 0
+	for _, r := range runes {
+		width += runeWidth(r)
+	}
+	return width
+}
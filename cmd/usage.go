@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+
+	"sakibox/internal/finder"
+	"sakibox/internal/voice"
+)
+
+const usageBarWidth = 20
+
+func analyzeUsage(reader *bufio.Reader) error {
+	path, err := promptPath(reader)
+	if err != nil {
+		return err
+	}
+	printYellow(voice.Line("finder_usage_scanning"))
+	var mu sync.Mutex
+	live := isTerminal()
+	ctx, stop := searchContext()
+	root, err := finder.AnalyzeUsage(ctx, path, func(p finder.UsageProgress) {
+		if !live {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if p.Done {
+			fmt.Print("\r\033[K")
+			return
+		}
+		fmt.Printf("\r\033[K  %s", truncateText(voice.Linef("finder_usage_progress", p.Items, finder.FormatSize(p.Size), p.Current), 100))
+	})
+	stop()
+	partial := errors.Is(err, context.Canceled)
+	if partial {
+		printYellow(voice.Line("finder_search_cancelled"))
+		err = nil
+	}
+	if err != nil {
+		printRed(err.Error())
+		return waitForEnter(reader)
+	}
+	if !live || !root.IsDir {
+		printUsageSummary(root)
+		return waitForEnter(reader)
+	}
+	return withRawTerminal(func() error {
+		fmt.Print("\033[?1049h\033[?25l")
+		defer fmt.Print("\033[?25h\033[?1049l")
+		return browseUsage(root, partial)
+	})
+}
+
+func printUsageSummary(root *finder.UsageNode) {
+	printMagenta(fmt.Sprintf("\n  %s", voice.Linef("finder_usage_total", root.Path, finder.FormatSize(root.Size), root.Files)))
+	for _, child := range root.Children {
+		fmt.Println(usageRow(root, child, 80))
+	}
+}
+
+type usageView struct {
+	node    *finder.UsageNode
+	partial bool
+	cursor  int
+	offset  int
+	status  string
+	pending *finder.UsageNode
+}
+
+func browseUsage(root *finder.UsageNode, partial bool) error {
+	view := &usageView{node: root, partial: partial}
+	for {
+		view.render()
+		events, err := readKeys()
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if view.pending != nil {
+				target := view.pending
+				view.pending = nil
+				if event.kind == keyRune && (event.r == 'y' || event.r == 'Y') {
+					view.status = deleteUsage(target)
+					view.cursor = min(view.cursor, max(len(view.node.Children)-1, 0))
+				} else {
+					view.status = voice.Line("finder_action_cancel")
+				}
+				continue
+			}
+			view.status = ""
+			children := view.node.Children
+			switch {
+			case event.kind == keyCtrlC || event.kind == keyEscape || event.kind == keyRune && event.r == 'q':
+				return nil
+			case event.kind == keyUp || event.kind == keyRune && event.r == 'k':
+				view.cursor = max(view.cursor-1, 0)
+			case event.kind == keyDown || event.kind == keyRune && event.r == 'j':
+				view.cursor = min(view.cursor+1, max(len(children)-1, 0))
+			case event.kind == keyPageUp:
+				view.cursor = max(view.cursor-view.rows(), 0)
+			case event.kind == keyPageDown:
+				view.cursor = min(view.cursor+view.rows(), max(len(children)-1, 0))
+			case event.kind == keyHome:
+				view.cursor = 0
+			case event.kind == keyEnd:
+				view.cursor = max(len(children)-1, 0)
+			case event.kind == keyRight || event.kind == keyEnter || event.kind == keyRune && event.r == 'l':
+				if view.cursor < len(children) && children[view.cursor].IsDir && len(children[view.cursor].Children) > 0 {
+					view.node, view.cursor, view.offset = children[view.cursor], 0, 0
+				}
+			case event.kind == keyLeft || event.kind == keyBackspace || event.kind == keyRune && event.r == 'h':
+				if view.node.Parent != nil {
+					from := view.node
+					view.node, view.cursor, view.offset = view.node.Parent, 0, 0
+					for i, child := range view.node.Children {
+						if child == from {
+							view.cursor = i
+						}
+					}
+				}
+			case event.kind == keyRune && event.r == 'd':
+				if view.cursor >= len(children) {
+					break
+				}
+				if reason := usageUndeletable(children[view.cursor]); reason != "" {
+					view.status = color.New(color.FgRed).Sprint(voice.Linef(reason, children[view.cursor].Path))
+					break
+				}
+				view.pending = children[view.cursor]
+				view.status = color.New(color.FgYellow).Sprint(voice.Linef("finder_usage_delete_confirm", view.pending.Path, finder.FormatSize(view.pending.Size)))
+				if view.partial {
+					view.status += color.New(color.FgYellow).Sprint(" " + voice.Line("finder_usage_partial"))
+				}
+			}
+		}
+	}
+}
+
+func deleteUsage(target *finder.UsageNode) string {
+	before := target.Size
+	err := os.RemoveAll(target.Path)
+	if err == nil {
+		target.Detach()
+		return color.New(color.FgGreen).Sprint(voice.Linef("finder_usage_deleted", target.Path, finder.FormatSize(before)))
+	}
+	if rescanErr := target.Rescan(context.Background()); rescanErr != nil {
+		err = errors.Join(err, rescanErr)
+	}
+	return color.New(color.FgRed).Sprint(voice.Linef("finder_usage_delete_partial", finder.FormatSize(max(before-target.Size, 0)), err))
+}
+
+func usageUndeletable(node *finder.UsageNode) string {
+	switch {
+	case node.Mount:
+		return "finder_usage_no_delete_mount"
+	case node.Failed || node.Partial:
+		return "finder_usage_no_delete_unread"
+	}
+	for _, child := range node.Children {
+		if reason := usageUndeletable(child); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+func (v *usageView) rows() int {
+	_, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || height < 10 {
+		height = 24
+	}
+	return height - 6
+}
+
+func (v *usageView) render() {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width < 40 {
+		width = 80
+	}
+	rows := v.rows()
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	if v.cursor >= v.offset+rows {
+		v.offset = v.cursor - rows + 1
+	}
+
+	var out strings.Builder
+	out.WriteString("\033[H\033[2J")
+	out.WriteString(color.New(color.FgCyan).Sprint("[磁盘占用] "))
+	out.WriteString(truncateText(v.node.Path, width-12))
+	out.WriteString("\r\n  ")
+	out.WriteString(color.New(color.FgMagenta).Sprint(voice.Linef("finder_usage_size", finder.FormatSize(v.node.Size), v.node.Files)))
+	if v.partial {
+		out.WriteString(color.New(color.FgYellow).Sprint("  " + voice.Line("finder_usage_partial")))
+	}
+	out.WriteString("\r\n\r\n")
+	children := v.node.Children
+	if len(children) == 0 {
+		out.WriteString(color.New(color.FgYellow).Sprint("  " + voice.Line("finder_usage_empty")))
+		out.WriteString("\r\n")
+	}
+	for i := v.offset; i < len(children) && i < v.offset+rows; i++ {
+		row := usageRow(v.node, children[i], width)
+		if i == v.cursor {
+			row = "\033[7m" + row + "\033[0m"
+		}
+		out.WriteString(row)
+		out.WriteString("\r\n")
+	}
+	for i := len(children) - v.offset; i < rows; i++ {
+		out.WriteString("\r\n")
+	}
+	out.WriteString(color.New(color.FgHiBlack).Sprint("  ↑↓ 选择  →/回车 进入  ←/退格 上一级  d 永久删除  q 退出"))
+	out.WriteString("\r\n  ")
+	out.WriteString(v.status)
+	_, _ = os.Stdout.WriteString(out.String())
+}
+
+func usageRow(parent, node *finder.UsageNode, width int) string {
+	percent := 0.0
+	if parent.Size > 0 {
+		percent = float64(node.Size) / float64(parent.Size) * 100
+	}
+	filled := int(percent/100*usageBarWidth + 0.5)
+	bar := strings.Repeat("#", filled) + strings.Repeat(" ", usageBarWidth-filled)
+	name := node.Name
+	switch {
+	case node.Mount:
+		name += "/ " + voice.Line("finder_usage_mount")
+	case node.Failed:
+		name += "/ " + voice.Line("finder_usage_failed")
+	case node.Partial:
+		name += "/ " + voice.Line("finder_usage_unscanned")
+	case node.IsDir:
+		name += "/"
+	}
+	prefix := fmt.Sprintf("  %8s %5.1f%% [%s] ", finder.FormatSize(node.Size), percent, bar)
+	return prefix + truncateText(name, max(width-len(prefix)-1, 10))
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+
+	"sakibox/internal/bookmark"
+	"sakibox/internal/placeholder"
+	"sakibox/internal/runlog"
+	"sakibox/internal/voice"
+)
+
+type stepResult struct {
+	label    string
+	status   string
+	exitCode int
+	duration time.Duration
+}
+
+const (
+	stepOK      = "OK"
+	stepFail    = "FAIL"
+	stepSkip    = "SKIP"
+	stepAbort   = "ABORT"
+	stepPending = "-"
+)
+
+func runWorkflow(reader *bufio.Reader, item bookmark.Item) error {
+	printCyan(fmt.Sprintf("\n  [%s] %s", voice.Line("workflow_title"), item.Name))
+	printWorkflowSteps(item)
+
+	texts := make([]string, 0, len(item.Steps))
+	for _, step := range item.Steps {
+		texts = append(texts, step.Command)
+	}
+	values, ok, err := fillPlaceholders(reader, strings.Join(texts, "\n"))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	results := make([]stepResult, len(item.Steps))
+	stopped := false
+	for i, step := range item.Steps {
+		results[i] = stepResult{label: step.Label(i), status: stepPending}
+		if stopped {
+			continue
+		}
+		command := placeholder.Fill(step.Command, values)
+		printCyan(fmt.Sprintf("\n  ▶ [%d/%d] %s", i+1, len(item.Steps), results[i].label))
+		printWhite("  $ " + command)
+
+		if step.Confirm != "" {
+			fmt.Printf("  %s", voice.Linef("workflow_confirm", step.Confirm))
+			answer, err := reader.ReadString('\n')
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "y":
+			case "q":
+				results[i].status = stepAbort
+				stopped = true
+				continue
+			default:
+				results[i].status = stepSkip
+				printYellow("  " + voice.Line("workflow_step_skipped"))
+				continue
+			}
+		}
+
+		run, err := runShellCommand(runlog.Spec{
+			Source:  "workflow",
+			Name:    item.Name + "/" + results[i].label,
+			Command: command,
+			Dir:     item.StepDir(step),
+			Env:     step.Environ(),
+		})
+		results[i].exitCode = run.ExitCode
+		results[i].duration = run.Duration()
+		if err == nil {
+			results[i].status = stepOK
+			printGreen("  ✔ " + voice.Line("workflow_step_ok"))
+			continue
+		}
+		results[i].status = stepFail
+		printRed("  ✘ " + err.Error())
+		if !step.ContinueOnError {
+			printYellow("  " + voice.Line("workflow_stopped"))
+			stopped = true
+		}
+	}
+
+	printWhite("\n  #   STEP                 STATUS  EXIT  DURATION")
+	failed := false
+	for i, result := range results {
+		line := fmt.Sprintf("  %-3d %-20s %-7s %-5d %s", i+1, truncateText(result.label, 20), result.status, result.exitCode, runlog.FormatDuration(result.duration))
+		switch result.status {
+		case stepOK:
+			printGreen(line)
+		case stepFail, stepAbort:
+			failed = true
+			printRed(line)
+		default:
+			if result.status == stepPending {
+				failed = true
+			}
+			printYellow(line)
+		}
+	}
+	if failed {
+		printRed(voice.Line("workflow_failed"))
+	} else {
+		printMagenta(voice.Line("workflow_done"))
+	}
+	return nil
+}
+
+func printWorkflowSteps(item bookmark.Item) {
+	for i, step := range item.Steps {
+		flags := make([]string, 0)
+		if step.Dir != "" {
+			flags = append(flags, "@ "+step.Dir)
+		}
+		if len(step.Env) > 0 {
+			flags = append(flags, strings.Join(step.Environ(), " "))
+		}
+		if step.ContinueOnError {
+			flags = append(flags, "continue-on-error")
+		}
+		if step.Confirm != "" {
+			flags = append(flags, "confirm")
+		}
+		line := fmt.Sprintf("      %d. %s: %s", i+1, step.Label(i), step.Command)
+		if len(flags) > 0 {
+			line += "  [" + strings.Join(flags, ", ") + "]"
+		}
+		printBlue(line)
+	}
+}
+
+func addWorkflow(reader *bufio.Reader) error {
+	fmt.Printf("\n  %s", voice.Line("bookmark_add_name_prompt"))
+	name, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  %s", voice.Line("bookmark_add_desc_prompt"))
+	description, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  %s", voice.Line("bookmark_add_tags_prompt"))
+	tags, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  %s", voice.Line("bookmark_add_folder_prompt"))
+	folder, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  %s", voice.Line("bookmark_add_dir_prompt"))
+	dir, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	item := bookmark.Item{
+		Name:        name,
+		Description: description,
+		Tags:        bookmark.ParseTags(tags),
+		Folder:      folder,
+		Dir:         dir,
+	}
+
+	printMagenta(voice.Line("workflow_steps_hint"))
+	for {
+		printCyan(fmt.Sprintf("\n  [%d]", len(item.Steps)+1))
+		fmt.Printf("  %s", voice.Line("workflow_step_cmd_prompt"))
+		command, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(command) == "" {
+			break
+		}
+		fmt.Printf("  %s", voice.Line("workflow_step_name_prompt"))
+		stepName, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %s", voice.Line("workflow_step_dir_prompt"))
+		stepDir, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %s", voice.Line("workflow_step_env_prompt"))
+		envInput, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %s", voice.Line("workflow_step_continue_prompt"))
+		continueInput, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %s", voice.Line("workflow_step_confirm_prompt"))
+		confirm, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		step := bookmark.Step{
+			Name:            strings.TrimSpace(stepName),
+			Command:         strings.TrimSpace(command),
+			Dir:             strings.TrimSpace(stepDir),
+			ContinueOnError: strings.ToLower(strings.TrimSpace(continueInput)) == "y",
+			Confirm:         strings.TrimSpace(confirm),
+		}
+		for _, pair := range strings.Fields(envInput) {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok || key == "" {
+				continue
+			}
+			if step.Env == nil {
+				step.Env = make(map[string]string)
+			}
+			step.Env[key] = value
+		}
+		item.Steps = append(item.Steps, step)
+	}
+
+	if err := bookmark.Add(item); err != nil {
+		printRed(err.Error())
+	} else {
+		printGreen(voice.Linef("workflow_add_success", len(item.Steps)))
+	}
+	return waitForEnter(reader)
+}
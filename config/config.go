@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 
 	"gopkg.in/yaml.v3"
+
+	"sakibox/internal/storage"
 )
 
 type Config struct {
@@ -13,6 +15,16 @@ type Config struct {
 	MaxHistory        int      `yaml:"max_history"`
 	DefaultSearchPath string   `yaml:"default_search_path"`
 	IgnoreDirs        []string `yaml:"ignore_dirs"`
+	SearchHidden      bool     `yaml:"search_hidden"`
+	RespectIgnore     bool     `yaml:"respect_ignore"`
+	UseIndex          bool     `yaml:"use_index"`
+	SearchArchives    bool     `yaml:"search_archives"`
+	SearchMaxDepth    int      `yaml:"search_max_depth"`
+	SearchMaxResults  int      `yaml:"search_max_results"`
+	SearchTimeout     int      `yaml:"search_timeout"`
+	CaptureOutput     bool     `yaml:"capture_output"`
+	SyncRemote        string   `yaml:"sync_remote"`
+	SyncBranch        string   `yaml:"sync_branch"`
 }
 
 func defaultConfig() Config {
@@ -22,6 +34,9 @@ func defaultConfig() Config {
 		MaxHistory:        50,
 		DefaultSearchPath: ".",
 		IgnoreDirs:        []string{"node_modules", ".git", "vendor"},
+		RespectIgnore:     true,
+		UseIndex:          true,
+		SyncBranch:        "main",
 	}
 }
 
@@ -70,8 +85,24 @@ func EnsureConfig() error {
 		return err
 	}
 
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+	return storage.WriteFileAtomic(path, data, 0644)
+}
+
+func Save(cfg Config) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := storage.Lock(path, true)
+	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+	defer unlock()
+	return storage.WriteFileAtomic(path, data, 0644)
 }
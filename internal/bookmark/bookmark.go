@@ -1,120 +1,369 @@
 package bookmark
 
 import (
-	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"sakibox/internal/fileops"
+	"sakibox/internal/storage"
 	"sakibox/internal/voice"
 )
 
 type Item struct {
-	Name    string `json:"name"`
-	Command string `json:"command"`
+	Name        string   `json:"name" yaml:"name"`
+	Command     string   `json:"command" yaml:"command"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Folder      string   `json:"folder,omitempty" yaml:"folder,omitempty"`
+	Dir         string   `json:"dir,omitempty" yaml:"dir,omitempty"`
+	CreatedAt   string   `json:"created_at,omitempty" yaml:"created_at,omitempty"`
+	LastUsed    string   `json:"last_used,omitempty" yaml:"last_used,omitempty"`
+	UseCount    int      `json:"use_count,omitempty" yaml:"use_count,omitempty"`
+	Steps       []Step   `json:"steps,omitempty" yaml:"steps,omitempty"`
+	Source      string   `json:"-" yaml:"-"`
 }
 
-func dataPath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(home, ".sakibox", "bookmarks.json"), nil
+type Step struct {
+	Name            string            `json:"name,omitempty" yaml:"name,omitempty"`
+	Command         string            `json:"command" yaml:"command"`
+	Dir             string            `json:"dir,omitempty" yaml:"dir,omitempty"`
+	Env             map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+	ContinueOnError bool              `json:"continue_on_error,omitempty" yaml:"continue_on_error,omitempty"`
+	Confirm         string            `json:"confirm,omitempty" yaml:"confirm,omitempty"`
 }
 
-func ensureData() error {
-	path, err := dataPath()
-	if err != nil {
-		return err
-	}
-	if _, err := os.Stat(path); err == nil {
-		return nil
-	}
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return err
-	}
-	data, err := json.MarshalIndent([]Item{}, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(path, data, 0644)
+type Entry struct {
+	Index int
+	Item  Item
+}
+
+type Filter struct {
+	Tag    string
+	Folder string
+	Sort   string
 }
 
+const (
+	SortDefault = ""
+	SortUsage   = "usage"
+	SortRecent  = "recent"
+	timeLayout  = "2006-01-02 15:04:05"
+)
+
+var Store = storage.Store{Name: "bookmarks.json", Version: 1}
+
 func List() ([]Item, error) {
-	if err := ensureData(); err != nil {
-		return nil, err
-	}
-	path, err := dataPath()
-	if err != nil {
-		return nil, err
-	}
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
 	items := make([]Item, 0)
-	if len(data) == 0 {
-		return items, nil
-	}
-	if err := json.Unmarshal(data, &items); err != nil {
+	if err := Store.Load(&items); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
 
-func Add(name, command string) error {
-	if strings.TrimSpace(name) == "" || strings.TrimSpace(command) == "" {
+func Add(item Item) error {
+	item = normalize(item)
+	if !item.Valid() {
 		return errors.New(voice.Line("bookmark_invalid_input"))
 	}
-	items, err := List()
-	if err != nil {
-		return err
+	if item.CreatedAt == "" {
+		item.CreatedAt = nowString()
 	}
-	items = append(items, Item{Name: name, Command: command})
-	return save(items)
+	items := make([]Item, 0)
+	return Store.Update(&items, func() error {
+		for _, existing := range items {
+			if existing.Name == item.Name {
+				return errors.New(voice.Line("bookmark_name_exists"))
+			}
+		}
+		items = append(items, item)
+		return nil
+	})
 }
 
-func Get(input string) (string, error) {
+func Get(input string) (Item, error) {
+	if rest, ok := strings.CutPrefix(input, projectPrefix); ok {
+		if index, err := strconv.Atoi(rest); err == nil {
+			project, found, err := CurrentProject()
+			if err != nil {
+				return Item{}, err
+			}
+			if !found || index <= 0 || index > len(project.Items) {
+				return Item{}, errors.New(voice.Line("invalid_index"))
+			}
+			return project.Items[index-1], nil
+		}
+	}
 	items, err := List()
 	if err != nil {
-		return "", err
+		return Item{}, err
 	}
 	if index, err := strconv.Atoi(input); err == nil {
 		if index <= 0 || index > len(items) {
-			return "", errors.New(voice.Line("invalid_index"))
+			return Item{}, errors.New(voice.Line("invalid_index"))
 		}
-		return items[index-1].Command, nil
+		return items[index-1], nil
 	}
 	for _, item := range items {
 		if item.Name == input {
-			return item.Command, nil
+			return item, nil
+		}
+	}
+	project, found, err := CurrentProject()
+	if err != nil {
+		return Item{}, err
+	}
+	if found {
+		for _, item := range project.Items {
+			if item.Name == input {
+				return item, nil
+			}
 		}
 	}
-	return "", errors.New(voice.Line("bookmark_not_found"))
+	return Item{}, errors.New(voice.Line("bookmark_not_found"))
+}
+
+func Update(name string, item Item) error {
+	item = normalize(item)
+	if !item.Valid() {
+		return errors.New(voice.Line("bookmark_invalid_input"))
+	}
+	items := make([]Item, 0)
+	return Store.Update(&items, func() error {
+		target := -1
+		for i, existing := range items {
+			if existing.Name == name {
+				target = i
+			} else if existing.Name == item.Name {
+				return errors.New(voice.Line("bookmark_name_exists"))
+			}
+		}
+		if target == -1 {
+			return errors.New(voice.Line("bookmark_not_found"))
+		}
+		items[target] = item
+		return nil
+	})
+}
+
+func MarkUsed(name string) error {
+	items := make([]Item, 0)
+	return Store.Update(&items, func() error {
+		for i := range items {
+			if items[i].Name == name {
+				items[i].UseCount++
+				items[i].LastUsed = nowString()
+				return nil
+			}
+		}
+		return errors.New(voice.Line("bookmark_not_found"))
+	})
 }
 
 func Delete(index int) error {
-	items, err := List()
-	if err != nil {
-		return err
+	items := make([]Item, 0)
+	return Store.Update(&items, func() error {
+		if index <= 0 || index > len(items) {
+			return errors.New(voice.Line("invalid_index"))
+		}
+		items = append(items[:index-1], items[index:]...)
+		return nil
+	})
+}
+
+func Apply(items []Item, filter Filter) []Entry {
+	entries := make([]Entry, 0, len(items))
+	for i, item := range items {
+		if filter.Folder != "" && !strings.EqualFold(item.Folder, filter.Folder) {
+			continue
+		}
+		if filter.Tag != "" && !hasTag(item, filter.Tag) {
+			continue
+		}
+		entries = append(entries, Entry{Index: i + 1, Item: item})
 	}
-	if index <= 0 || index > len(items) {
-		return errors.New(voice.Line("invalid_index"))
+	switch filter.Sort {
+	case SortUsage:
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].Item.UseCount != entries[j].Item.UseCount {
+				return entries[i].Item.UseCount > entries[j].Item.UseCount
+			}
+			return entries[i].Item.LastUsed > entries[j].Item.LastUsed
+		})
+	case SortRecent:
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Item.LastUsed > entries[j].Item.LastUsed
+		})
 	}
-	items = append(items[:index-1], items[index:]...)
-	return save(items)
+	return entries
 }
 
-func save(items []Item) error {
-	path, err := dataPath()
-	if err != nil {
-		return err
+func Folders(items []Item) []string {
+	return collect(items, func(item Item) []string {
+		return []string{item.Folder}
+	})
+}
+
+func Tags(items []Item) []string {
+	return collect(items, func(item Item) []string {
+		return item.Tags
+	})
+}
+
+func ParseTags(input string) []string {
+	tags := make([]string, 0)
+	for _, tag := range strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '，'
+	}) {
+		tags = append(tags, strings.TrimPrefix(tag, "#"))
 	}
-	data, err := json.MarshalIndent(items, "", "  ")
-	if err != nil {
-		return err
+	return tags
+}
+
+func hasTag(item Item, tag string) bool {
+	tag = strings.TrimPrefix(tag, "#")
+	for _, value := range item.Tags {
+		if strings.EqualFold(value, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func collect(items []Item, values func(Item) []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0)
+	for _, item := range items {
+		for _, value := range values(item) {
+			if value == "" || seen[value] {
+				continue
+			}
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func normalize(item Item) Item {
+	item.Name = strings.TrimSpace(item.Name)
+	item.Command = strings.TrimSpace(item.Command)
+	item.Description = strings.TrimSpace(item.Description)
+	item.Folder = strings.TrimSpace(item.Folder)
+	item.Dir = strings.TrimSpace(item.Dir)
+	tags := make([]string, 0, len(item.Tags))
+	for _, tag := range item.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	item.Tags = tags
+	steps := make([]Step, 0, len(item.Steps))
+	for _, step := range item.Steps {
+		step.Name = strings.TrimSpace(step.Name)
+		step.Command = strings.TrimSpace(step.Command)
+		step.Dir = strings.TrimSpace(step.Dir)
+		step.Confirm = strings.TrimSpace(step.Confirm)
+		if step.Command != "" {
+			steps = append(steps, step)
+		}
+	}
+	item.Steps = nil
+	if len(steps) > 0 {
+		item.Steps = steps
+	}
+	return item
+}
+
+func (item Item) Valid() bool {
+	return item.Name != "" && (item.Command != "" || len(item.Steps) > 0)
+}
+
+func (item Item) IsWorkflow() bool {
+	return len(item.Steps) > 0
+}
+
+func (item Item) StepDir(step Step) string {
+	dir := ResolveDir(step.Dir)
+	if dir == "" {
+		return ResolveDir(item.Dir)
+	}
+	if !filepath.IsAbs(dir) && item.Dir != "" {
+		return filepath.Join(ResolveDir(item.Dir), dir)
+	}
+	return dir
+}
+
+func (step Step) Environ() []string {
+	env := make([]string, 0, len(step.Env))
+	for key, value := range step.Env {
+		env = append(env, key+"="+value)
 	}
-	return os.WriteFile(path, data, 0644)
+	sort.Strings(env)
+	return env
+}
+
+func (step Step) Label(index int) string {
+	if step.Name != "" {
+		return step.Name
+	}
+	return fmt.Sprintf("step %d", index+1)
+}
+
+func (item Item) Script() string {
+	if !item.IsWorkflow() {
+		return item.Command
+	}
+	steps := make([]string, 0, len(item.Steps))
+	for i, step := range item.Steps {
+		command := "{ " + step.Command + "; }"
+		if step.Dir != "" {
+			command = fmt.Sprintf("cd %s && %s", shellDir(step.Dir), command)
+		}
+		if env := step.Environ(); len(env) > 0 {
+			quoted := make([]string, 0, len(env))
+			for _, pair := range env {
+				key, value, _ := strings.Cut(pair, "=")
+				quoted = append(quoted, key+"="+fileops.ShellQuote(value))
+			}
+			command = fmt.Sprintf("export %s; %s", strings.Join(quoted, " "), command)
+		}
+		if step.Dir != "" || len(step.Env) > 0 {
+			command = "( " + command + " )"
+		}
+		if !step.ContinueOnError && i < len(item.Steps)-1 {
+			command += " || exit $?"
+		}
+		steps = append(steps, command)
+	}
+	return "( " + strings.Join(steps, "; ") + " )"
+}
+
+func shellDir(dir string) string {
+	switch {
+	case dir == "~" || dir == "~/":
+		return "~"
+	case strings.HasPrefix(dir, "~/"):
+		return "~/" + fileops.ShellQuote(strings.TrimPrefix(dir, "~/"))
+	}
+	return fileops.ShellQuote(dir)
+}
+
+func ResolveDir(dir string) string {
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(dir, "~"))
+		}
+	}
+	return dir
+}
+
+func nowString() string {
+	return time.Now().Format(timeLayout)
 }
@@ -0,0 +1,83 @@
+package bookmark
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Project struct {
+	Root  string
+	Path  string
+	Items []Item
+}
+
+type projectFile struct {
+	Bookmarks []Item `yaml:"bookmarks"`
+}
+
+const (
+	ProjectFileName = ".sakibox.yaml"
+	projectPrefix   = "p"
+)
+
+func CurrentProject() (Project, bool, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return Project{}, false, err
+	}
+	return FindProject(wd)
+}
+
+func FindProject(start string) (Project, bool, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return Project{}, false, err
+	}
+	for {
+		path := filepath.Join(dir, ProjectFileName)
+		if _, err := os.Stat(path); err == nil {
+			project, err := loadProject(dir, path)
+			return project, err == nil, err
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return Project{}, false, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return Project{}, false, nil
+		}
+		dir = parent
+	}
+}
+
+func ProjectKey(index int) string {
+	return projectPrefix + strconv.Itoa(index)
+}
+
+func loadProject(root, path string) (Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Project{}, err
+	}
+	var file projectFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return Project{}, err
+	}
+	project := Project{Root: root, Path: path, Items: make([]Item, 0, len(file.Bookmarks))}
+	for _, item := range file.Bookmarks {
+		item = normalize(item)
+		if !item.Valid() {
+			continue
+		}
+		item.Dir = ResolveDir(item.Dir)
+		if !filepath.IsAbs(item.Dir) {
+			item.Dir = filepath.Join(root, item.Dir)
+		}
+		item.Source = path
+		project.Items = append(project.Items, item)
+	}
+	return project, nil
+}
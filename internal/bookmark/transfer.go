@@ -0,0 +1,479 @@
+package bookmark
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"sakibox/internal/fileops"
+	"sakibox/internal/placeholder"
+	"sakibox/internal/storage"
+	"sakibox/internal/voice"
+)
+
+const (
+	FormatAlias = "alias"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+)
+
+var (
+	makeTargetPattern  = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_./ -]*?)\s*::?(?:\s|$)`)
+	justRecipePattern  = regexp.MustCompile(`^@?([A-Za-z][A-Za-z0-9_-]*)((?:\s+[^:]*)?)\s*:(?:[^=]|$)`)
+	justParamPattern   = regexp.MustCompile(`^([+*$]?)([A-Za-z_][A-Za-z0-9_-]*)(?:=(.*))?$`)
+	fishEscapePattern  = regexp.MustCompile(`\\x([0-9a-fA-F]{2})`)
+	invalidNamePattern = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+)
+
+func DetectSources(dir string) []string {
+	candidates := make([]string, 0)
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates,
+			filepath.Join(home, ".bashrc"),
+			filepath.Join(home, ".bash_aliases"),
+			filepath.Join(home, ".zshrc"),
+			filepath.Join(home, ".config", "fish", "config.fish"),
+			filepath.Join(home, ".config", "fish", "fish_variables"),
+		)
+	}
+	for _, name := range []string{"Makefile", "makefile", "GNUmakefile", "justfile", "Justfile", ".justfile", "package.json"} {
+		candidates = append(candidates, filepath.Join(dir, name))
+	}
+	sources := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, path := range candidates {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		real, err := filepath.EvalSymlinks(path)
+		if err != nil || seen[real] {
+			continue
+		}
+		seen[real] = true
+		sources = append(sources, path)
+	}
+	return sources
+}
+
+func ImportFile(path string) ([]Item, error) {
+	path, err := filepath.Abs(ResolveDir(path))
+	if err != nil {
+		return nil, err
+	}
+	base := filepath.Base(path)
+	switch {
+	case base == "package.json":
+		return parsePackageJSON(path)
+	case strings.EqualFold(base, "justfile") || base == ".justfile":
+		return parseJustfile(path)
+	case base == "Makefile" || base == "makefile" || base == "GNUmakefile" || strings.HasSuffix(base, ".mk"):
+		return parseMakefile(path)
+	case base == "fish_variables" || strings.HasSuffix(base, ".fish"):
+		return parseFish(path)
+	case strings.HasSuffix(base, ".json"):
+		return parseJSONItems(path)
+	case strings.HasSuffix(base, ".yaml") || strings.HasSuffix(base, ".yml"):
+		project, err := loadProject(filepath.Dir(path), path)
+		if err != nil {
+			return nil, err
+		}
+		for i := range project.Items {
+			project.Items[i].Source = ""
+		}
+		return project.Items, nil
+	default:
+		return parseShellAliases(path)
+	}
+}
+
+func Import(items []Item) (int, int, error) {
+	added, skipped := 0, 0
+	existing := make([]Item, 0)
+	err := Store.Update(&existing, func() error {
+		names := make(map[string]bool)
+		for _, item := range existing {
+			names[item.Name] = true
+		}
+		for _, item := range items {
+			item = normalize(item)
+			if !item.Valid() || names[item.Name] {
+				skipped++
+				continue
+			}
+			names[item.Name] = true
+			item.Source = ""
+			item.UseCount = 0
+			item.LastUsed = ""
+			if item.CreatedAt == "" {
+				item.CreatedAt = nowString()
+			}
+			existing = append(existing, item)
+			added++
+		}
+		if added == 0 {
+			return storage.ErrUnchanged
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return added, skipped, nil
+}
+
+func Export(items []Item, format string) ([]byte, error) {
+	exported := make([]Item, 0, len(items))
+	for _, item := range items {
+		item.UseCount = 0
+		item.LastUsed = ""
+		exported = append(exported, item)
+	}
+	switch format {
+	case FormatJSON:
+		return json.MarshalIndent(exported, "", "  ")
+	case FormatYAML:
+		return yaml.Marshal(projectFile{Bookmarks: exported})
+	case FormatAlias:
+		return exportAliases(exported), nil
+	default:
+		return nil, errors.New(voice.Line("invalid_option"))
+	}
+}
+
+func exportAliases(items []Item) []byte {
+	var out strings.Builder
+	out.WriteString("# generated by sakibox, source this file from your shell rc\n")
+	for _, item := range items {
+		name := strings.Trim(invalidNamePattern.ReplaceAllString(item.Name, "-"), "-")
+		if name == "" {
+			continue
+		}
+		command := item.Script()
+		fields := placeholder.Parse(command)
+		if item.Description != "" {
+			out.WriteString("# " + strings.ReplaceAll(item.Description, "\n", " ") + "\n")
+		}
+		if len(fields) == 0 {
+			if item.Dir != "" {
+				command = fmt.Sprintf("(cd %s && %s)", shellDir(item.Dir), command)
+			}
+			out.WriteString(fmt.Sprintf("alias %s=%s\n", name, fileops.ShellQuote(command)))
+			continue
+		}
+		values := make(map[string]string)
+		for i, field := range fields {
+			if field.Default != "" {
+				values[field.Name] = fmt.Sprintf("\"${%d:-%s}\"", i+1, strings.ReplaceAll(field.Default, "\"", "\\\""))
+			} else {
+				values[field.Name] = fmt.Sprintf("\"$%d\"", i+1)
+			}
+		}
+		command = placeholder.Fill(command, values)
+		if item.Dir != "" {
+			command = fmt.Sprintf("(cd %s && %s)", shellDir(item.Dir), command)
+		}
+		out.WriteString(fmt.Sprintf("unalias %s 2>/dev/null\n%s() { %s; }\n", name, name, command))
+	}
+	return []byte(out.String())
+}
+
+func parseShellAliases(path string) ([]Item, error) {
+	lines, err := readFileLines(path)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Item, 0)
+	for _, line := range lines {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line), "alias ")
+		if !ok {
+			continue
+		}
+		words := splitShellWords(rest)
+		if len(words) > 0 && (words[0] == "-g" || words[0] == "-s") {
+			continue
+		}
+		for _, word := range words {
+			if strings.HasPrefix(word, "-") {
+				continue
+			}
+			name, command, ok := strings.Cut(word, "=")
+			if !ok || name == "" || command == "" {
+				continue
+			}
+			items = append(items, importedItem(name, command, "alias", ""))
+		}
+	}
+	return items, nil
+}
+
+func parseFish(path string) ([]Item, error) {
+	lines, err := readFileLines(path)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Item, 0)
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(line, "SETUVAR _fish_abbr_"); ok {
+			name, command, ok := strings.Cut(rest, ":")
+			if !ok {
+				continue
+			}
+			items = append(items, importedItem(unescapeFishVar(name), unescapeFishVar(command), "fish", ""))
+			continue
+		}
+		words := splitShellWords(line)
+		if len(words) < 2 {
+			continue
+		}
+		switch words[0] {
+		case "abbr":
+			args := make([]string, 0)
+			for _, word := range words[1:] {
+				if strings.HasPrefix(word, "-") {
+					continue
+				}
+				args = append(args, word)
+			}
+			if len(args) >= 2 {
+				items = append(items, importedItem(args[0], strings.Join(args[1:], " "), "fish", ""))
+			}
+		case "alias":
+			if name, command, ok := strings.Cut(words[1], "="); ok {
+				items = append(items, importedItem(name, command, "fish", ""))
+			} else if len(words) >= 3 {
+				items = append(items, importedItem(words[1], strings.Join(words[2:], " "), "fish", ""))
+			}
+		}
+	}
+	return items, nil
+}
+
+func parseMakefile(path string) ([]Item, error) {
+	lines, err := readFileLines(path)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(path)
+	items := make([]Item, 0)
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		if strings.HasPrefix(line, "\t") || strings.Contains(line, ":=") || strings.Contains(line, "=") && !strings.Contains(line, ":") {
+			continue
+		}
+		match := makeTargetPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		for _, target := range strings.Fields(match[1]) {
+			if strings.HasPrefix(target, ".") || strings.ContainsAny(target, "%$/") || seen[target] {
+				continue
+			}
+			seen[target] = true
+			items = append(items, importedItem("make:"+target, "make "+target, "make", dir))
+		}
+	}
+	return items, nil
+}
+
+func parseJustfile(path string) ([]Item, error) {
+	lines, err := readFileLines(path)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(path)
+	items := make([]Item, 0)
+	description := ""
+	for _, line := range lines {
+		if strings.HasPrefix(line, "#") {
+			description = strings.TrimSpace(strings.TrimLeft(line, "#"))
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "[") {
+			continue
+		}
+		match := justRecipePattern.FindStringSubmatch(line)
+		if match == nil {
+			description = ""
+			continue
+		}
+		name := match[1]
+		switch name {
+		case "set", "alias", "export", "import", "mod":
+			description = ""
+			continue
+		}
+		if strings.HasPrefix(name, "_") {
+			description = ""
+			continue
+		}
+		command := "just " + name
+		for _, param := range strings.Fields(match[2]) {
+			parts := justParamPattern.FindStringSubmatch(param)
+			if parts == nil {
+				continue
+			}
+			value := strings.Trim(parts[3], `'"`)
+			if value != "" {
+				command += fmt.Sprintf(" {{%s:%s}}", parts[2], value)
+			} else {
+				command += fmt.Sprintf(" {{%s}}", parts[2])
+			}
+		}
+		item := importedItem("just:"+name, command, "just", dir)
+		item.Description = description
+		items = append(items, item)
+		description = ""
+	}
+	return items, nil
+}
+
+func parsePackageJSON(path string) ([]Item, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(path)
+	runner := "npm run"
+	for _, lock := range []struct{ file, runner string }{
+		{"pnpm-lock.yaml", "pnpm run"},
+		{"yarn.lock", "yarn run"},
+		{"bun.lock", "bun run"},
+		{"bun.lockb", "bun run"},
+	} {
+		if _, err := os.Stat(filepath.Join(dir, lock.file)); err == nil {
+			runner = lock.runner
+			break
+		}
+	}
+	names := make([]string, 0, len(pkg.Scripts))
+	for name := range pkg.Scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	items := make([]Item, 0, len(names))
+	for _, name := range names {
+		item := importedItem("npm:"+name, runner+" "+name, "npm", dir)
+		item.Description = pkg.Scripts[name]
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func parseJSONItems(path string) ([]Item, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err = Store.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Item, 0)
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func importedItem(name, command, source, dir string) Item {
+	return Item{
+		Name:    strings.TrimSpace(name),
+		Command: strings.TrimSpace(command),
+		Tags:    []string{source},
+		Folder:  source,
+		Dir:     dir,
+	}
+}
+
+func readFileLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	return lines, scanner.Err()
+}
+
+func splitShellWords(input string) []string {
+	words := make([]string, 0)
+	var current strings.Builder
+	inWord := false
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case c == '#' && !inWord:
+			return words
+		case c == ' ' || c == '\t' || c == ';':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+			if c == ';' {
+				return words
+			}
+		case c == '\'':
+			inWord = true
+			end := strings.IndexByte(input[i+1:], '\'')
+			if end == -1 {
+				current.WriteString(input[i+1:])
+				i = len(input)
+				continue
+			}
+			current.WriteString(input[i+1 : i+1+end])
+			i += end + 1
+		case c == '"':
+			inWord = true
+			for i++; i < len(input) && input[i] != '"'; i++ {
+				if input[i] == '\\' && i+1 < len(input) && strings.IndexByte("\"\\$`", input[i+1]) != -1 {
+					i++
+				}
+				current.WriteByte(input[i])
+			}
+		case c == '\\' && i+1 < len(input):
+			inWord = true
+			i++
+			current.WriteByte(input[i])
+		default:
+			inWord = true
+			current.WriteByte(c)
+		}
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words
+}
+
+func unescapeFishVar(value string) string {
+	return fishEscapePattern.ReplaceAllStringFunc(value, func(token string) string {
+		code, err := strconv.ParseUint(token[2:], 16, 8)
+		if err != nil {
+			return token
+		}
+		return string(rune(code))
+	})
+}
@@ -0,0 +1,161 @@
+package bookmark
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func describe(items []Item) []string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		line := item.Name + " => " + item.Command
+		if item.Description != "" {
+			line += " # " + item.Description
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+func TestImportFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []string
+	}{
+		{
+			name: "shell aliases",
+			file: ".bashrc",
+			content: "alias ll='ls -la'\n" +
+				"alias gs=\"git status\" # status\n" +
+				"alias -g G='| grep'\n" +
+				"  alias k=kubectl; echo hi\n" +
+				"alias a1='echo 1' a2=pwd\n" +
+				"export X=1\n",
+			want: []string{"ll => ls -la", "gs => git status", "k => kubectl", "a1 => echo 1", "a2 => pwd"},
+		},
+		{
+			name: "fish config",
+			file: "config.fish",
+			content: "abbr -a gco git checkout\n" +
+				"alias gp 'git push'\n" +
+				"alias gl='git log'\n",
+			want: []string{"gco => git checkout", "gp => git push", "gl => git log"},
+		},
+		{
+			name:    "fish variables",
+			file:    "fish_variables",
+			content: "# This file contains fish universal variable definitions.\nSETUVAR _fish_abbr_gst:git\\x20status\nSETUVAR fish_color_cwd:green\n",
+			want:    []string{"gst => git status"},
+		},
+		{
+			name: "makefile",
+			file: "Makefile",
+			content: ".PHONY: build test\n" +
+				"VAR = x\n" +
+				"CC := gcc\n" +
+				"build: deps\n" +
+				"\tgo build\n" +
+				"test lint:\n" +
+				"%.o: %.c\n" +
+				"install:: build\n" +
+				"build:\n" +
+				"dist/app: build\n",
+			want: []string{"make:build => make build", "make:test => make test", "make:lint => make lint", "make:install => make install"},
+		},
+		{
+			name: "justfile",
+			file: "justfile",
+			content: "set shell := [\"bash\", \"-c\"]\n" +
+				"\n" +
+				"# Build the app\n" +
+				"build:\n" +
+				"    go build\n" +
+				"\n" +
+				"deploy env='dev' +args: build\n" +
+				"    echo {{env}}\n" +
+				"\n" +
+				"_hidden:\n" +
+				"[private]\n" +
+				"@quiet:\n" +
+				"alias b := build\n",
+			want: []string{"just:build => just build # Build the app", "just:deploy => just deploy {{env:dev}} {{args}}", "just:quiet => just quiet"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			items, err := ImportFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := describe(items); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportPackageJSON(t *testing.T) {
+	tests := []struct {
+		locks []string
+		want  string
+	}{
+		{nil, "npm run"},
+		{[]string{"yarn.lock"}, "yarn run"},
+		{[]string{"bun.lockb"}, "bun run"},
+		{[]string{"yarn.lock", "pnpm-lock.yaml"}, "pnpm run"},
+		{[]string{"bun.lock", "yarn.lock"}, "yarn run"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "package.json")
+		if err := os.WriteFile(path, []byte(`{"scripts":{"test":"jest","build":"tsc -p ."}}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		for _, lock := range tt.locks {
+			if err := os.WriteFile(filepath.Join(dir, lock), nil, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		items, err := ImportFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"npm:build => " + tt.want + " build # tsc -p .", "npm:test => " + tt.want + " test # jest"}
+		if got := describe(items); !reflect.DeepEqual(got, want) {
+			t.Errorf("locks %v: got %q, want %q", tt.locks, got, want)
+		}
+		for _, item := range items {
+			if item.Dir != dir {
+				t.Errorf("%s runs in %q, want %q", item.Name, item.Dir, dir)
+			}
+		}
+	}
+}
+
+func TestSplitShellWords(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"a b  c", []string{"a", "b", "c"}},
+		{`x='a b' y="c \"d\""`, []string{"x=a b", `y=c "d"`}},
+		{`a\ b c`, []string{"a b", "c"}},
+		{"a # comment", []string{"a"}},
+		{"a; b", []string{"a"}},
+		{"'unclosed", []string{"unclosed"}},
+	}
+	for _, tt := range tests {
+		if got := splitShellWords(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitShellWords(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,439 @@
+package fileops
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"sakibox/internal/storage"
+	"sakibox/internal/voice"
+)
+
+type TrashItem struct {
+	Original string `json:"original"`
+	Stored   string `json:"stored"`
+}
+
+type TrashBatch struct {
+	ID    string      `json:"id"`
+	Time  time.Time   `json:"time"`
+	Items []TrashItem `json:"items"`
+}
+
+const (
+	FormatTarGz = "tar.gz"
+	FormatZip   = "zip"
+	maxBatches  = 50
+)
+
+var trashStore = storage.Store{Name: "trash.json", Version: 1}
+
+func Trash(paths []string) (TrashBatch, error) {
+	dir, err := storage.Path("trash")
+	if err != nil {
+		return TrashBatch{}, err
+	}
+	now := time.Now()
+	batch := TrashBatch{ID: strconv.FormatInt(now.UnixNano(), 36), Time: now, Items: make([]TrashItem, 0, len(paths))}
+	target := filepath.Join(dir, batch.ID)
+	if err := os.MkdirAll(target, 0700); err != nil {
+		return TrashBatch{}, err
+	}
+	var failed error
+	for i, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			failed = errors.Join(failed, err)
+			continue
+		}
+		stored := filepath.Join(target, fmt.Sprintf("%d-%s", i+1, filepath.Base(abs)))
+		if err := movePath(abs, stored); err != nil {
+			failed = errors.Join(failed, err)
+			continue
+		}
+		batch.Items = append(batch.Items, TrashItem{Original: abs, Stored: stored})
+	}
+	if len(batch.Items) == 0 {
+		_ = os.Remove(target)
+		return batch, failed
+	}
+	batches := make([]TrashBatch, 0)
+	err = trashStore.Update(&batches, func() error {
+		batches = append(batches, batch)
+		if len(batches) > maxBatches {
+			for _, old := range batches[:len(batches)-maxBatches] {
+				_ = os.RemoveAll(filepath.Join(dir, old.ID))
+			}
+			batches = batches[len(batches)-maxBatches:]
+		}
+		return nil
+	})
+	return batch, errors.Join(failed, err)
+}
+
+func UndoTrash() (TrashBatch, error) {
+	var restored TrashBatch
+	var failed error
+	batches := make([]TrashBatch, 0)
+	err := trashStore.Update(&batches, func() error {
+		if len(batches) == 0 {
+			return errors.New(voice.Line("trash_empty"))
+		}
+		last := &batches[len(batches)-1]
+		restored = *last
+		restored.Items = make([]TrashItem, 0, len(last.Items))
+		for _, item := range last.Items {
+			if _, err := os.Lstat(item.Original); err == nil {
+				return errors.New(voice.Linef("trash_restore_exists", item.Original))
+			}
+		}
+		for len(last.Items) > 0 {
+			item := last.Items[0]
+			err := os.MkdirAll(filepath.Dir(item.Original), 0755)
+			if err == nil {
+				err = movePath(item.Stored, item.Original)
+			}
+			if err != nil && len(restored.Items) == 0 {
+				return err
+			}
+			if err != nil {
+				failed = err
+				return nil
+			}
+			restored.Items = append(restored.Items, item)
+			last.Items = last.Items[1:]
+		}
+		if dir, err := storage.Path("trash"); err == nil {
+			_ = os.Remove(filepath.Join(dir, restored.ID))
+		}
+		batches = batches[:len(batches)-1]
+		return nil
+	})
+	return restored, errors.Join(failed, err)
+}
+
+func LastTrash() (TrashBatch, bool, error) {
+	batches := make([]TrashBatch, 0)
+	if err := trashStore.Load(&batches); err != nil {
+		return TrashBatch{}, false, err
+	}
+	if len(batches) == 0 {
+		return TrashBatch{}, false, nil
+	}
+	return batches[len(batches)-1], true, nil
+}
+
+func Move(paths []string, target string) ([]string, error) {
+	return transfer(paths, target, movePath)
+}
+
+func Copy(paths []string, target string) ([]string, error) {
+	return transfer(paths, target, copyPath)
+}
+
+func Destination(path, target string) string {
+	return filepath.Join(target, filepath.Base(path))
+}
+
+func transfer(paths []string, target string, op func(src, dst string) error) ([]string, error) {
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return nil, err
+	}
+	done := make([]string, 0, len(paths))
+	var failed error
+	for _, path := range paths {
+		dst := Destination(path, target)
+		if _, err := os.Lstat(dst); err == nil {
+			failed = errors.Join(failed, errors.New(voice.Linef("fileops_exists", dst)))
+			continue
+		}
+		if err := op(path, dst); err != nil {
+			failed = errors.Join(failed, err)
+			continue
+		}
+		done = append(done, dst)
+	}
+	return done, failed
+}
+
+func movePath(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+	var linkErr *os.LinkError
+	if !errors.As(err, &linkErr) || !errors.Is(linkErr.Err, syscall.EXDEV) {
+		return err
+	}
+	if err := copyPath(src, dst); err != nil {
+		_ = os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+func copyPath(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case entry.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case entry.Type().IsRegular():
+			return copyFile(path, target, info)
+		}
+		return nil
+	})
+}
+
+func copyFile(src, dst string, info fs.FileInfo) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+func Hardlink(keep string, paths []string) ([]string, error) {
+	keepInfo, err := os.Stat(keep)
+	if err != nil {
+		return nil, err
+	}
+	done := make([]string, 0, len(paths))
+	var failed error
+	for _, path := range paths {
+		info, err := os.Lstat(path)
+		if err != nil {
+			failed = errors.Join(failed, err)
+			continue
+		}
+		if os.SameFile(keepInfo, info) {
+			continue
+		}
+		tmp := filepath.Join(filepath.Dir(path), fmt.Sprintf(".%s.sakibox-link", filepath.Base(path)))
+		if err := os.Link(keep, tmp); err != nil {
+			failed = errors.Join(failed, err)
+			continue
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			_ = os.Remove(tmp)
+			failed = errors.Join(failed, err)
+			continue
+		}
+		done = append(done, path)
+	}
+	return done, failed
+}
+
+func TotalSize(paths []string) (int64, int, error) {
+	var total int64
+	files := 0
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if entry.Type().IsRegular() {
+				if info, err := entry.Info(); err == nil {
+					total += info.Size()
+					files++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return total, files, err
+		}
+	}
+	return total, files, nil
+}
+
+func Archive(paths []string, dest, format string) (int, error) {
+	base := commonDir(paths)
+	absDest, err := filepath.Abs(dest)
+	if err != nil {
+		return 0, err
+	}
+	file, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	add := func(write func(path, name string, info fs.FileInfo) error) error {
+		for _, path := range paths {
+			err := filepath.WalkDir(path, func(current string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				abs, err := filepath.Abs(current)
+				if err != nil {
+					return err
+				}
+				if abs == absDest || !entry.IsDir() && !entry.Type().IsRegular() {
+					return nil
+				}
+				info, err := entry.Info()
+				if err != nil {
+					return err
+				}
+				name, err := filepath.Rel(base, abs)
+				if err != nil {
+					return err
+				}
+				if entry.Type().IsRegular() {
+					count++
+				}
+				return write(current, filepath.ToSlash(name), info)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch format {
+	case FormatZip:
+		zw := zip.NewWriter(file)
+		err = add(func(path, name string, info fs.FileInfo) error {
+			header, err := zip.FileInfoHeader(info)
+			if err != nil {
+				return err
+			}
+			header.Name = name
+			if info.IsDir() {
+				header.Name += "/"
+				_, err = zw.CreateHeader(header)
+				return err
+			}
+			header.Method = zip.Deflate
+			w, err := zw.CreateHeader(header)
+			if err != nil {
+				return err
+			}
+			return copyInto(w, path)
+		})
+		err = errors.Join(err, zw.Close())
+	default:
+		gw := gzip.NewWriter(file)
+		tw := tar.NewWriter(gw)
+		err = add(func(path, name string, info fs.FileInfo) error {
+			header, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			header.Name = name
+			if info.IsDir() {
+				header.Name += "/"
+			}
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			return copyInto(tw, path)
+		})
+		err = errors.Join(err, tw.Close(), gw.Close())
+	}
+	err = errors.Join(err, file.Close())
+	if err != nil {
+		_ = os.Remove(dest)
+		return 0, err
+	}
+	return count, nil
+}
+
+func copyInto(w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
+
+func commonDir(paths []string) string {
+	base := ""
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			continue
+		}
+		dir := filepath.Dir(abs)
+		if base == "" {
+			base = dir
+			continue
+		}
+		for base != dir && !strings.HasPrefix(dir, base+string(filepath.Separator)) && filepath.Dir(base) != base {
+			base = filepath.Dir(base)
+		}
+	}
+	return base
+}
+
+func EditorCommand(path string, line int) string {
+	editor := strings.TrimSpace(os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("EDITOR"))
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	quoted := ShellQuote(path)
+	if line <= 0 {
+		return editor + " " + quoted
+	}
+	fields := strings.Fields(editor)
+	switch filepath.Base(fields[0]) {
+	case "code", "codium", "code-insiders", "cursor":
+		return fmt.Sprintf("%s -g %s", editor, ShellQuote(fmt.Sprintf("%s:%d", path, line)))
+	case "subl", "zed", "hx", "helix":
+		return fmt.Sprintf("%s %s", editor, ShellQuote(fmt.Sprintf("%s:%d", path, line)))
+	default:
+		return fmt.Sprintf("%s +%d %s", editor, line, quoted)
+	}
+}
+
+func ShellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,145 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, names ...string) []string {
+	t.Helper()
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestTrashAndUndo(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	names := []string{"a/x", "b/y"}
+	paths := writeFiles(t, t.TempDir(), names...)
+	batch, err := Trash(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch.Items) != 2 {
+		t.Fatalf("trashed %d items, want 2", len(batch.Items))
+	}
+	for _, path := range paths {
+		if _, err := os.Lstat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists after Trash", path)
+		}
+	}
+	last, ok, err := LastTrash()
+	if err != nil || !ok || last.ID != batch.ID {
+		t.Fatalf("LastTrash = %v, %v, %v, want batch %s", last.ID, ok, err, batch.ID)
+	}
+	restored, err := UndoTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored.Items) != 2 {
+		t.Errorf("restored %d items, want 2", len(restored.Items))
+	}
+	for i, path := range paths {
+		if data, err := os.ReadFile(path); err != nil || string(data) != names[i] {
+			t.Errorf("%s = %q, %v after undo, want %q", path, data, err, names[i])
+		}
+	}
+	if _, err := UndoTrash(); err == nil {
+		t.Error("UndoTrash with nothing trashed succeeded, want error")
+	}
+}
+
+func TestUndoTrashRefusesExisting(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths := writeFiles(t, t.TempDir(), "x")
+	if _, err := Trash(paths); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, filepath.Dir(paths[0]), "x")
+	if _, err := UndoTrash(); err == nil {
+		t.Fatal("UndoTrash over an existing file succeeded, want error")
+	}
+	if _, ok, _ := LastTrash(); !ok {
+		t.Error("refused undo dropped the batch")
+	}
+}
+
+func TestUndoTrashPartial(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	paths := writeFiles(t, dir, "a/x", "b/y")
+	if _, err := Trash(paths); err != nil {
+		t.Fatal(err)
+	}
+	blocked := filepath.Join(dir, "b")
+	if err := os.Remove(blocked); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(blocked, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := UndoTrash()
+	if err == nil {
+		t.Fatal("UndoTrash into a blocked directory succeeded, want error")
+	}
+	if len(restored.Items) != 1 || restored.Items[0].Original != paths[0] {
+		t.Fatalf("restored %+v, want only %s", restored.Items, paths[0])
+	}
+	last, ok, err := LastTrash()
+	if err != nil || !ok || len(last.Items) != 1 || last.Items[0].Original != paths[1] {
+		t.Fatalf("remaining batch = %+v, %v, %v, want only %s", last.Items, ok, err, paths[1])
+	}
+	if err := os.Remove(blocked); err != nil {
+		t.Fatal(err)
+	}
+	restored, err = UndoTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored.Items) != 1 || restored.Items[0].Original != paths[1] {
+		t.Errorf("second undo restored %+v, want %s", restored.Items, paths[1])
+	}
+	if _, ok, _ := LastTrash(); ok {
+		t.Error("batch left behind after a complete undo")
+	}
+}
+
+func TestHardlink(t *testing.T) {
+	dir := t.TempDir()
+	paths := writeFiles(t, dir, "keep", "dup1", "sub/dup2")
+	if err := os.Link(paths[0], filepath.Join(dir, "already")); err != nil {
+		t.Fatal(err)
+	}
+	targets := []string{paths[1], paths[2], filepath.Join(dir, "already"), filepath.Join(dir, "missing")}
+	done, err := Hardlink(paths[0], targets)
+	if err == nil {
+		t.Error("Hardlink of a missing path succeeded, want error")
+	}
+	if len(done) != 2 || done[0] != paths[1] || done[1] != paths[2] {
+		t.Errorf("linked %q, want %q", done, paths[1:])
+	}
+	keep, err := os.Stat(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range done {
+		info, err := os.Stat(path)
+		if err != nil || !os.SameFile(keep, info) {
+			t.Errorf("%s is not a link to keep", path)
+		}
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(dir, "*", ".*.sakibox-link"))
+	if more, _ := filepath.Glob(filepath.Join(dir, ".*.sakibox-link")); len(leftovers)+len(more) > 0 {
+		t.Errorf("temporary links left behind: %q %q", leftovers, more)
+	}
+}
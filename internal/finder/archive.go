@@ -0,0 +1,210 @@
+package finder
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+)
+
+const ArchiveSeparator = "!"
+
+type archiveKind int
+
+const (
+	archiveNone archiveKind = iota
+	archiveZip
+	archiveTar
+	archiveStream
+)
+
+var archiveSuffixes = []struct {
+	suffix string
+	kind   archiveKind
+	codec  string
+}{
+	{".tar.gz", archiveTar, "gz"},
+	{".tgz", archiveTar, "gz"},
+	{".tar.bz2", archiveTar, "bz2"},
+	{".tbz2", archiveTar, "bz2"},
+	{".tar.xz", archiveTar, "xz"},
+	{".txz", archiveTar, "xz"},
+	{".tar", archiveTar, ""},
+	{".zip", archiveZip, ""},
+	{".jar", archiveZip, ""},
+	{".war", archiveZip, ""},
+	{".gz", archiveStream, "gz"},
+	{".bz2", archiveStream, "bz2"},
+	{".xz", archiveStream, "xz"},
+}
+
+func archiveType(name string) (archiveKind, string, string) {
+	lower := strings.ToLower(name)
+	for _, item := range archiveSuffixes {
+		if strings.HasSuffix(lower, item.suffix) {
+			return item.kind, item.codec, name[:len(name)-len(item.suffix)]
+		}
+	}
+	return archiveNone, "", ""
+}
+
+func IsArchive(name string) bool {
+	kind, _, _ := archiveType(name)
+	return kind != archiveNone
+}
+
+func SplitArchivePath(result string) (string, string, bool) {
+	for offset := 0; ; {
+		index := strings.Index(result[offset:], ArchiveSeparator)
+		if index < 0 {
+			return result, "", false
+		}
+		index += offset
+		if IsArchive(result[:index]) {
+			return result[:index], result[index+len(ArchiveSeparator):], true
+		}
+		offset = index + len(ArchiveSeparator)
+	}
+}
+
+func walkArchive(ctx context.Context, name string, fn func(inner string, info fs.FileInfo, r io.Reader) error) error {
+	kind, codec, stem := archiveType(name)
+	switch kind {
+	case archiveZip:
+		archive, err := zip.OpenReader(name)
+		if err != nil {
+			return err
+		}
+		defer archive.Close()
+		for _, member := range archive.File {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if member.FileInfo().IsDir() {
+				continue
+			}
+			r, err := member.Open()
+			if err != nil {
+				continue
+			}
+			err = fn(path.Clean(member.Name), member.FileInfo(), r)
+			r.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case archiveTar, archiveStream:
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		stream, closeStream, err := decompress(ctx, name, file, codec)
+		if err != nil {
+			return err
+		}
+		defer closeStream()
+		if kind == archiveStream {
+			info, err := file.Stat()
+			if err != nil {
+				return err
+			}
+			inner := path.Base(stem)
+			if gz, ok := stream.(*gzip.Reader); ok && gz.Name != "" {
+				inner = gz.Name
+			}
+			return fn(inner, streamInfo{name: inner, modTime: info.ModTime()}, stream)
+		}
+		archive := tar.NewReader(stream)
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			header, err := archive.Next()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if header.Typeflag != tar.TypeReg {
+				continue
+			}
+			if err := fn(path.Clean(header.Name), header.FileInfo(), archive); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func decompress(ctx context.Context, name string, file io.Reader, codec string) (io.Reader, func(), error) {
+	switch codec {
+	case "gz":
+		stream, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		return stream, func() { stream.Close() }, nil
+	case "bz2":
+		return bzip2.NewReader(file), func() {}, nil
+	case "xz":
+		cmd := exec.CommandContext(ctx, "xz", "-dc", name)
+		stream, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, nil, err
+		}
+		return stream, func() {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+		}, nil
+	}
+	return file, func() {}, nil
+}
+
+type streamInfo struct {
+	name    string
+	modTime time.Time
+}
+
+func (i streamInfo) Name() string       { return i.name }
+func (i streamInfo) Size() int64        { return 0 }
+func (i streamInfo) Mode() fs.FileMode  { return 0644 }
+func (i streamInfo) ModTime() time.Time { return i.modTime }
+func (i streamInfo) IsDir() bool        { return false }
+func (i streamInfo) Sys() any           { return nil }
+
+func (s *contentSearch) archive(ctx context.Context, name, ext string, buf []byte) ([]ContentResult, int, int, error) {
+	results := make([]ContentResult, 0)
+	scanned, binary := 0, 0
+	err := walkArchive(ctx, name, func(inner string, info fs.FileInfo, r io.Reader) error {
+		if ext != "" && !strings.EqualFold(path.Ext(inner), ext) {
+			return nil
+		}
+		found, isBinary := s.read(ctx, name+ArchiveSeparator+inner, r, buf)
+		if isBinary {
+			binary++
+			return nil
+		}
+		scanned++
+		results = append(results, found...)
+		return nil
+	})
+	if ctx.Err() != nil {
+		err = nil
+	}
+	return results, scanned, binary, err
+}
@@ -0,0 +1,197 @@
+package finder
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	archive := zip.NewWriter(out)
+	if _, err := archive.Create("dir/"); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTarGz(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	gz := gzip.NewWriter(out)
+	archive := tar.NewWriter(gz)
+	if err := archive.WriteHeader(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "a.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := archive.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(archive, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeGz(t *testing.T, path, name, content string) {
+	t.Helper()
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	gz := gzip.NewWriter(out)
+	gz.Name = name
+	if _, err := io.WriteString(gz, content); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func archiveMembers(t *testing.T, path string) []string {
+	t.Helper()
+	members := make([]string, 0)
+	err := walkArchive(context.Background(), path, func(inner string, _ fs.FileInfo, r io.Reader) error {
+		data, err := io.ReadAll(r)
+		members = append(members, inner+"="+string(data))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(members)
+	return members
+}
+
+func TestWalkArchive(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{"a.txt": "alpha", "sub/b.txt": "beta"}
+	want := []string{"a.txt=alpha", "sub/b.txt=beta"}
+
+	zipPath := filepath.Join(dir, "x.zip")
+	writeZip(t, zipPath, files)
+	if got := archiveMembers(t, zipPath); !reflect.DeepEqual(got, want) {
+		t.Errorf("zip members = %q, want %q", got, want)
+	}
+
+	tgzPath := filepath.Join(dir, "x.tar.gz")
+	writeTarGz(t, tgzPath, files)
+	if got := archiveMembers(t, tgzPath); !reflect.DeepEqual(got, want) {
+		t.Errorf("tar.gz members = %q, want %q", got, want)
+	}
+
+	gzPath := filepath.Join(dir, "server.log.gz")
+	writeGz(t, gzPath, "", "line")
+	if got := archiveMembers(t, gzPath); !reflect.DeepEqual(got, []string{"server.log=line"}) {
+		t.Errorf("gz members = %q", got)
+	}
+	writeGz(t, gzPath, "original.log", "line")
+	if got := archiveMembers(t, gzPath); !reflect.DeepEqual(got, []string{"original.log=line"}) {
+		t.Errorf("named gz members = %q", got)
+	}
+}
+
+func TestWalkArchiveFailures(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.zip")
+	if err := os.WriteFile(bad, []byte("not a zip"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	noop := func(string, fs.FileInfo, io.Reader) error { return nil }
+	if err := walkArchive(context.Background(), bad, noop); err == nil {
+		t.Error("corrupt zip walked without error")
+	}
+	xz := filepath.Join(dir, "a.txt.xz")
+	if err := os.WriteFile(xz, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", t.TempDir())
+	if err := walkArchive(context.Background(), xz, noop); err == nil {
+		t.Error("xz walked without an xz command, want error")
+	}
+}
+
+func TestSearchContentCountsFailedArchives(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	writeZip(t, filepath.Join(dir, "good.zip"), map[string]string{"a.txt": "needle"})
+	if err := os.WriteFile(filepath.Join(dir, "bad.zip"), []byte("not a zip"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var last Progress
+	stream, err := SearchContent(context.Background(), dir, "needle", ContentOptions{Archives: true, Progress: func(p Progress) {
+		if p.Done {
+			last = p
+		}
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make([]string, 0)
+	for batch := range stream {
+		for _, result := range batch {
+			paths = append(paths, result.Path)
+		}
+	}
+	want := []string{filepath.Join(dir, "good.zip") + ArchiveSeparator + "a.txt"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("matches = %q, want %q", paths, want)
+	}
+	if last.Failed != 1 {
+		t.Errorf("Failed = %d, want 1", last.Failed)
+	}
+}
+
+func TestSplitArchivePath(t *testing.T) {
+	tests := []struct {
+		input, archive, inner string
+		ok                    bool
+	}{
+		{"logs.tar.gz!app/server.log", "logs.tar.gz", "app/server.log", true},
+		{"a!b/c.zip!d.txt", "a!b/c.zip", "d.txt", true},
+		{"plain!name.txt", "plain!name.txt", "", false},
+		{"x.jar!META-INF/MANIFEST.MF", "x.jar", "META-INF/MANIFEST.MF", true},
+	}
+	for _, tt := range tests {
+		archive, inner, ok := SplitArchivePath(tt.input)
+		if archive != tt.archive || inner != tt.inner || ok != tt.ok {
+			t.Errorf("SplitArchivePath(%q) = %q, %q, %v, want %q, %q, %v", tt.input, archive, inner, ok, tt.archive, tt.inner, tt.ok)
+		}
+	}
+}
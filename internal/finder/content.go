@@ -0,0 +1,458 @@
+package finder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"sakibox/internal/voice"
+)
+
+type ContentResult struct {
+	Path    string
+	Line    int
+	Content string
+	Spans   []Span
+	Before  []ContextLine
+	After   []ContextLine
+}
+
+type Span struct {
+	Start int
+	End   int
+}
+
+type ContextLine struct {
+	Line    int
+	Content string
+}
+
+type Progress struct {
+	Files   int64
+	Scanned int64
+	Binary  int64
+	Failed  int64
+	Matches int64
+	Current string
+	Done    bool
+	Err     error
+}
+
+type ContentOptions struct {
+	Ext        string
+	Regex      bool
+	IgnoreCase bool
+	WholeWord  bool
+	Invert     bool
+	Archives   bool
+	Before     int
+	After      int
+	Workers    int
+	Progress   func(Progress)
+}
+
+const (
+	binarySniffSize  = 8 * 1024
+	readChunkSize    = 1024 * 1024
+	progressInterval = 100 * time.Millisecond
+)
+
+var chunkPool = sync.Pool{New: func() any {
+	buf := make([]byte, readChunkSize)
+	return &buf
+}}
+
+func FindByContent(ctx context.Context, root, query string) ([]ContentResult, error) {
+	return FindByContentWithExt(ctx, root, query, "")
+}
+
+func FindByContentWithExt(ctx context.Context, root, query, ext string) ([]ContentResult, error) {
+	var stopped error
+	stream, err := SearchContent(ctx, root, query, ContentOptions{Ext: ext, Progress: func(p Progress) {
+		if p.Done {
+			stopped = p.Err
+		}
+	}})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]ContentResult, 0)
+	for batch := range stream {
+		results = append(results, batch...)
+	}
+	SortContentResults(results)
+	return results, stopped
+}
+
+func SortContentResults(results []ContentResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Path != results[j].Path {
+			return results[i].Path < results[j].Path
+		}
+		return results[i].Line < results[j].Line
+	})
+}
+
+func SearchContent(ctx context.Context, root, query string, opts ContentOptions) (<-chan []ContentResult, error) {
+	walk, err := loadWalkOptions()
+	if err != nil {
+		return nil, err
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	ext := normalizeExt(opts.Ext)
+	archives := opts.Archives || walk.searchArchives
+	search, err := newContentSearch(query, opts)
+	if err != nil {
+		return nil, err
+	}
+	parent := ctx
+	ctx, cancel := walk.budget(ctx)
+
+	var stats struct {
+		files, scanned, binary, failed, matches atomic.Int64
+		reserved                                atomic.Int64
+		limited                                 atomic.Bool
+		current                                 atomic.Value
+	}
+	stats.current.Store("")
+	snapshot := func(done bool) Progress {
+		var err error
+		switch {
+		case !done:
+		case stats.limited.Load():
+			err = ErrMaxResults
+		case parent.Err() != nil:
+			err = parent.Err()
+		case ctx.Err() != nil:
+			err = context.DeadlineExceeded
+		}
+		return Progress{
+			Files:   stats.files.Load(),
+			Scanned: stats.scanned.Load(),
+			Binary:  stats.binary.Load(),
+			Failed:  stats.failed.Load(),
+			Matches: stats.matches.Load(),
+			Current: stats.current.Load().(string),
+			Done:    done,
+			Err:     err,
+		}
+	}
+
+	paths := make(chan string, workers*64)
+	results := make(chan []ContentResult, workers*64)
+
+	go func() {
+		defer close(paths)
+		_ = forEachFile(ctx, root, walk, func(path string, entry os.DirEntry) error {
+			if ext != "" && !strings.EqualFold(filepath.Ext(entry.Name()), ext) && !(archives && IsArchive(entry.Name())) {
+				return nil
+			}
+			stats.files.Add(1)
+			select {
+			case paths <- path:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := chunkPool.Get().(*[]byte)
+			defer chunkPool.Put(buf)
+			for path := range paths {
+				if ctx.Err() != nil {
+					continue
+				}
+				stats.current.Store(path)
+				var found []ContentResult
+				if archives && IsArchive(path) {
+					var scanned, binary int
+					var err error
+					found, scanned, binary, err = search.archive(ctx, path, ext, *buf)
+					stats.scanned.Add(int64(scanned))
+					stats.binary.Add(int64(binary))
+					if err != nil {
+						stats.failed.Add(1)
+					}
+				} else {
+					var binary bool
+					found, binary = search.file(ctx, path, *buf)
+					if binary {
+						stats.binary.Add(1)
+						continue
+					}
+					stats.scanned.Add(1)
+				}
+				if walk.maxResults > 0 {
+					if over := stats.reserved.Add(int64(len(found))) - int64(walk.maxResults); over > 0 {
+						found = found[:max(len(found)-int(over), 0)]
+						stats.limited.Store(true)
+						cancel()
+					}
+				}
+				if len(found) == 0 {
+					continue
+				}
+				select {
+				case results <- found:
+					stats.matches.Add(int64(len(found)))
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+
+	stop := make(chan struct{})
+	var reporter sync.WaitGroup
+	if opts.Progress != nil {
+		reporter.Add(1)
+		go func() {
+			defer reporter.Done()
+			ticker := time.NewTicker(progressInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					opts.Progress(snapshot(false))
+				case <-stop:
+					opts.Progress(snapshot(true))
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(stop)
+		reporter.Wait()
+		cancel()
+		close(results)
+	}()
+	return results, nil
+}
+
+type contentSearch struct {
+	literal   []byte
+	re        *regexp.Regexp
+	prefilter *regexp.Regexp
+	invert    bool
+	before    int
+	after     int
+	firstOnly bool
+}
+
+func newContentSearch(query string, opts ContentOptions) (*contentSearch, error) {
+	search := &contentSearch{
+		invert: opts.Invert,
+		before: max(opts.Before, 0),
+		after:  max(opts.After, 0),
+	}
+	if !opts.Regex && !opts.IgnoreCase && !opts.WholeWord {
+		search.literal = []byte(query)
+		return search, nil
+	}
+	pattern := query
+	if !opts.Regex {
+		pattern = regexp.QuoteMeta(query)
+	}
+	if opts.WholeWord {
+		pattern = `\b(?:` + pattern + `)\b`
+	}
+	if opts.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", voice.Line("finder_invalid_regex"), err)
+	}
+	search.re = re
+	if !strings.Contains(pattern, `\A`) && !strings.Contains(pattern, `\z`) {
+		search.prefilter, _ = regexp.Compile("(?m)" + pattern)
+	}
+	return search, nil
+}
+
+func (s *contentSearch) chunkMayMatch(chunk []byte) bool {
+	if s.invert || s.before > 0 || s.after > 0 {
+		return true
+	}
+	if s.re == nil {
+		return bytes.Contains(chunk, s.literal)
+	}
+	if s.prefilter == nil {
+		return true
+	}
+	return s.prefilter.Match(chunk)
+}
+
+func (s *contentSearch) match(line []byte) ([]Span, bool) {
+	spans := make([]Span, 0)
+	if s.re == nil {
+		for offset := 0; offset <= len(line); {
+			index := bytes.Index(line[offset:], s.literal)
+			if index < 0 {
+				break
+			}
+			start := offset + index
+			spans = append(spans, Span{Start: start, End: start + len(s.literal)})
+			offset = start + max(len(s.literal), 1)
+		}
+	} else {
+		for _, loc := range s.re.FindAllIndex(line, -1) {
+			spans = append(spans, Span{Start: loc[0], End: loc[1]})
+		}
+	}
+	if s.invert {
+		return nil, len(spans) == 0
+	}
+	return spans, len(spans) > 0
+}
+
+type fileScan struct {
+	search  *contentSearch
+	path    string
+	results []ContentResult
+	recent  []ContextLine
+	open    []int
+}
+
+func (f *fileScan) line(num int, raw []byte) {
+	spans, ok := f.search.match(raw)
+	text := strings.TrimRightFunc(string(raw), unicode.IsSpace)
+	if f.search.after > 0 {
+		pending := f.open[:0]
+		for _, index := range f.open {
+			result := &f.results[index]
+			result.After = append(result.After, ContextLine{Line: num, Content: text})
+			if len(result.After) < f.search.after {
+				pending = append(pending, index)
+			}
+		}
+		f.open = pending
+	}
+	if ok {
+		content := strings.TrimLeftFunc(text, unicode.IsSpace)
+		shift := len(text) - len(content)
+		trimmed := make([]Span, 0, len(spans))
+		for _, span := range spans {
+			start, end := max(span.Start-shift, 0), min(span.End-shift, len(content))
+			if end > start {
+				trimmed = append(trimmed, Span{Start: start, End: end})
+			}
+		}
+		result := ContentResult{Path: f.path, Line: num, Content: content, Spans: trimmed}
+		if len(f.recent) > 0 {
+			result.Before = append([]ContextLine(nil), f.recent...)
+		}
+		f.results = append(f.results, result)
+		if f.search.after > 0 {
+			f.open = append(f.open, len(f.results)-1)
+		}
+	}
+	if f.search.before > 0 {
+		if len(f.recent) == f.search.before {
+			f.recent = f.recent[1:]
+		}
+		f.recent = append(f.recent, ContextLine{Line: num, Content: text})
+	}
+}
+
+func (s *contentSearch) file(ctx context.Context, path string, buf []byte) ([]ContentResult, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+	return s.read(ctx, path, file, buf)
+}
+
+func (s *contentSearch) read(ctx context.Context, path string, file io.Reader, buf []byte) ([]ContentResult, bool) {
+	scan := &fileScan{search: s, path: path, results: make([]ContentResult, 0)}
+	lineNum := 0
+	pending := 0
+	first := true
+	for {
+		if ctx.Err() != nil {
+			return scan.results, false
+		}
+		if pending == len(buf) {
+			grown := make([]byte, len(buf)*2)
+			copy(grown, buf[:pending])
+			buf = grown
+		}
+		n, err := io.ReadFull(file, buf[pending:])
+		data := buf[:pending+n]
+		if first {
+			first = false
+			if bytes.IndexByte(data[:min(len(data), binarySniffSize)], 0) >= 0 {
+				return nil, true
+			}
+		}
+		eof := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+		if err != nil && !eof {
+			return scan.results, false
+		}
+
+		complete := data
+		if !eof {
+			cut := bytes.LastIndexByte(data, '\n')
+			if cut < 0 {
+				pending = len(data)
+				continue
+			}
+			complete = data[:cut+1]
+		}
+		rest := data[len(complete):]
+		if !s.chunkMayMatch(complete) {
+			lineNum += bytes.Count(complete, []byte{'\n'})
+		} else {
+			for len(complete) > 0 {
+				end := bytes.IndexByte(complete, '\n')
+				line := complete
+				if end >= 0 {
+					line, complete = complete[:end], complete[end+1:]
+				} else {
+					complete = nil
+				}
+				lineNum++
+				scan.line(lineNum, line)
+				if s.firstOnly && len(scan.results) > 0 {
+					return scan.results, false
+				}
+			}
+		}
+		if eof {
+			return scan.results, false
+		}
+		pending = copy(buf, rest)
+	}
+}
+
+func normalizeExt(ext string) string {
+	ext = strings.TrimSpace(ext)
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
@@ -0,0 +1,175 @@
+package finder
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSearchContentBatchesPerFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	want := make(map[string]int)
+	for i := range 20 {
+		path := filepath.Join(dir, fmt.Sprintf("f%02d.txt", i))
+		lines := make([]string, 0, 50)
+		for j := range 50 {
+			if j%(i%5+1) == 0 {
+				lines = append(lines, "a needle here")
+				want[path]++
+			} else {
+				lines = append(lines, "hay")
+			}
+		}
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bin.dat"), []byte("needle\x00"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var last Progress
+	stream, err := SearchContent(context.Background(), dir, "needle", ContentOptions{Workers: 4, Progress: func(p Progress) {
+		if p.Done {
+			last = p
+		}
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]int)
+	for batch := range stream {
+		path := batch[0].Path
+		if got[path] > 0 {
+			t.Errorf("%s arrived in more than one batch", path)
+		}
+		for i, result := range batch {
+			if result.Path != path {
+				t.Errorf("batch for %s holds a result from %s", path, result.Path)
+			}
+			if i > 0 && result.Line <= batch[i-1].Line {
+				t.Errorf("%s: line %d after line %d", path, result.Line, batch[i-1].Line)
+			}
+			got[path]++
+		}
+	}
+	for path, count := range want {
+		if got[path] != count {
+			t.Errorf("%s: %d matches, want %d", path, got[path], count)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("matched %d files, want %d", len(got), len(want))
+	}
+	if last.Binary != 1 || last.Scanned != 20 || last.Err != nil {
+		t.Errorf("final progress = %+v, want 20 scanned, 1 binary, no error", last)
+	}
+}
+
+func TestContentReadChunks(t *testing.T) {
+	long := strings.Repeat("x", 100) + "needle"
+	text := "needle first\n" + long + "\nno\nlast needle"
+	search, err := newContentSearch("needle", ContentOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, binary := search.read(context.Background(), "f", strings.NewReader(text), make([]byte, 16))
+	if binary {
+		t.Fatal("text reported as binary")
+	}
+	lines := make([]int, 0, len(results))
+	for _, result := range results {
+		lines = append(lines, result.Line)
+	}
+	if fmt.Sprint(lines) != "[1 2 4]" {
+		t.Errorf("matched lines %v, want [1 2 4]", lines)
+	}
+	if results[1].Content != long || results[1].Spans[0] != (Span{Start: 100, End: 106}) {
+		t.Errorf("long line = %q %v", results[1].Content, results[1].Spans)
+	}
+	if _, binary := search.read(context.Background(), "f", strings.NewReader("needle\x00"), make([]byte, 16)); !binary {
+		t.Error("NUL byte not reported as binary")
+	}
+}
+
+func TestContentSearchModes(t *testing.T) {
+	text := "alpha\nfoo bar\nfood\nbeta\nFOO\ngamma\n"
+	tests := []struct {
+		name  string
+		query string
+		opts  ContentOptions
+		want  string
+	}{
+		{"literal", "foo", ContentOptions{}, "2:[0 3] 3:[0 3]"},
+		{"ignore case", "foo", ContentOptions{IgnoreCase: true}, "2:[0 3] 3:[0 3] 5:[0 3]"},
+		{"whole word", "foo", ContentOptions{WholeWord: true}, "2:[0 3]"},
+		{"whole word ignore case", "foo", ContentOptions{WholeWord: true, IgnoreCase: true}, "2:[0 3] 5:[0 3]"},
+		{"regex", `^[a-z]+a$`, ContentOptions{Regex: true}, "1:[0 5] 4:[0 4] 6:[0 5]"},
+		{"invert", "a", ContentOptions{Invert: true}, "3:[] 5:[]"},
+		{"literal meta characters", "o.b", ContentOptions{IgnoreCase: true}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			search, err := newContentSearch(tt.query, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results, _ := search.read(context.Background(), "f", strings.NewReader(text), make([]byte, 64))
+			got := make([]string, 0, len(results))
+			for _, result := range results {
+				spans := make([]string, 0, len(result.Spans))
+				for _, span := range result.Spans {
+					spans = append(spans, fmt.Sprint(span.Start, span.End))
+				}
+				got = append(got, fmt.Sprintf("%d:[%s]", result.Line, strings.Join(spans, ",")))
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("got %q, want %q", strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
+
+func TestContentContextLines(t *testing.T) {
+	lines := make([]string, 12)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("l%d", i+1)
+	}
+	lines[2], lines[4], lines[10] = "hit", "hit", "hit"
+	search, err := newContentSearch("hit", ContentOptions{Before: 2, After: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, _ := search.read(context.Background(), "f", strings.NewReader(strings.Join(lines, "\n")), make([]byte, 64))
+	context := func(items []ContextLine) []int {
+		nums := make([]int, 0, len(items))
+		for _, item := range items {
+			nums = append(nums, item.Line)
+		}
+		return nums
+	}
+	want := []struct {
+		line          int
+		before, after string
+	}{
+		{3, "[1 2]", "[4]"},
+		{5, "[3 4]", "[6]"},
+		{11, "[9 10]", "[12]"},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Line != w.line || fmt.Sprint(context(r.Before)) != w.before || fmt.Sprint(context(r.After)) != w.after {
+			t.Errorf("result %d = line %d before %v after %v, want line %d before %s after %s",
+				i, r.Line, context(r.Before), context(r.After), w.line, w.before, w.after)
+		}
+	}
+	if results[0].Before[0].Content != "l1" || results[2].After[0].Content != "l12" {
+		t.Errorf("context content = %q, %q", results[0].Before[0].Content, results[2].After[0].Content)
+	}
+}
@@ -0,0 +1,235 @@
+package finder
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const partialHashSize = 4 * 1024
+
+type DuplicateFile struct {
+	Path    string
+	ModTime time.Time
+	inode   uint64
+}
+
+type DuplicateGroup struct {
+	Size  int64
+	Hash  string
+	Files []DuplicateFile
+}
+
+type DuplicateProgress struct {
+	Files  int64
+	Hashed int64
+	Total  int64
+	Done   bool
+}
+
+func (g DuplicateGroup) Wasted() int64 {
+	return g.Size * int64(g.copies()-1)
+}
+
+func (g DuplicateGroup) copies() int {
+	seen := make(map[uint64]bool)
+	copies := 0
+	for _, file := range g.Files {
+		if file.inode != 0 {
+			if seen[file.inode] {
+				continue
+			}
+			seen[file.inode] = true
+		}
+		copies++
+	}
+	return copies
+}
+
+func (g DuplicateGroup) Newest() int {
+	newest := 0
+	for i, file := range g.Files {
+		if file.ModTime.After(g.Files[newest].ModTime) {
+			newest = i
+		}
+	}
+	return newest
+}
+
+func FindDuplicates(ctx context.Context, root string, minSize int64, progress func(DuplicateProgress)) ([]DuplicateGroup, error) {
+	opts, err := loadWalkOptions()
+	if err != nil {
+		return nil, err
+	}
+	opts.skipLinks = true
+	minSize = max(minSize, 1)
+	var stats DuplicateProgress
+	report := func(done bool) {
+		if progress != nil {
+			snapshot := DuplicateProgress{
+				Files:  atomic.LoadInt64(&stats.Files),
+				Hashed: atomic.LoadInt64(&stats.Hashed),
+				Total:  atomic.LoadInt64(&stats.Total),
+				Done:   done,
+			}
+			progress(snapshot)
+		}
+	}
+
+	bySize := make(map[int64][]DuplicateFile)
+	err = forEachFile(ctx, root, opts, func(path string, entry os.DirEntry) error {
+		info, err := entry.Info()
+		if err != nil || info.Size() < minSize {
+			return nil
+		}
+		bySize[info.Size()] = append(bySize[info.Size()], DuplicateFile{Path: path, ModTime: info.ModTime(), inode: inode(info)})
+		if atomic.AddInt64(&stats.Files, 1)%1000 == 0 {
+			report(false)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]DuplicateGroup, 0)
+	for size, files := range bySize {
+		group := DuplicateGroup{Size: size, Files: files}
+		if group.copies() > 1 {
+			candidates = append(candidates, group)
+		}
+	}
+	candidates, err = splitByHash(ctx, candidates, true, &stats, report)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := splitByHash(ctx, candidates, false, &stats, report)
+	if err != nil {
+		return nil, err
+	}
+	report(true)
+
+	for _, group := range groups {
+		sort.Slice(group.Files, func(i, j int) bool {
+			return group.Files[i].Path < group.Files[j].Path
+		})
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Wasted() != groups[j].Wasted() {
+			return groups[i].Wasted() > groups[j].Wasted()
+		}
+		return groups[i].Files[0].Path < groups[j].Files[0].Path
+	})
+	return groups, nil
+}
+
+func splitByHash(ctx context.Context, groups []DuplicateGroup, partial bool, stats *DuplicateProgress, report func(bool)) ([]DuplicateGroup, error) {
+	type job struct {
+		group, file int
+	}
+	hashes := make([][]string, len(groups))
+	jobs := make(chan job)
+	total := 0
+	for i, group := range groups {
+		hashes[i] = make([]string, len(group.Files))
+		total += len(group.Files)
+	}
+	atomic.StoreInt64(&stats.Hashed, 0)
+	atomic.StoreInt64(&stats.Total, int64(total))
+
+	var wg sync.WaitGroup
+	for n := 0; n < runtime.NumCPU(); n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				group := groups[j.group]
+				small := group.Size <= 2*partialHashSize
+				hashes[j.group][j.file] = hashFile(group.Files[j.file].Path, group.Size, partial && !small)
+				if atomic.AddInt64(&stats.Hashed, 1)%100 == 0 {
+					report(false)
+				}
+			}
+		}()
+	}
+	var err error
+feed:
+	for i, group := range groups {
+		if !partial && group.Hash != "" && group.Size <= 2*partialHashSize {
+			for f := range group.Files {
+				hashes[i][f] = group.Hash
+			}
+			continue
+		}
+		for f := range group.Files {
+			select {
+			case jobs <- job{group: i, file: f}:
+			case <-ctx.Done():
+				err = ctx.Err()
+				break feed
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	split := make([]DuplicateGroup, 0, len(groups))
+	for i, group := range groups {
+		byHash := make(map[string][]DuplicateFile)
+		order := make([]string, 0)
+		for f, file := range group.Files {
+			hash := hashes[i][f]
+			if hash == "" {
+				continue
+			}
+			if _, ok := byHash[hash]; !ok {
+				order = append(order, hash)
+			}
+			byHash[hash] = append(byHash[hash], file)
+		}
+		for _, hash := range order {
+			next := DuplicateGroup{Size: group.Size, Hash: hash, Files: byHash[hash]}
+			if next.copies() > 1 {
+				split = append(split, next)
+			}
+		}
+	}
+	return split, nil
+}
+
+func hashFile(path string, size int64, partial bool) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if partial {
+		head := make([]byte, partialHashSize)
+		if _, err := io.ReadFull(file, head); err != nil {
+			return ""
+		}
+		hash.Write(head)
+		if _, err := file.ReadAt(head, size-partialHashSize); err != nil {
+			return ""
+		}
+		hash.Write(head)
+	} else {
+		buf := chunkPool.Get().(*[]byte)
+		defer chunkPool.Put(buf)
+		if _, err := io.CopyBuffer(hash, file, *buf); err != nil {
+			return ""
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,79 @@
+package finder
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	big := bytes.Repeat([]byte("0123456789abcdef"), 2048)
+	near := bytes.Clone(big)
+	near[len(near)/2] = 'x'
+	files := map[string][]byte{
+		"a.txt":      []byte("hello world"),
+		"sub/b.txt":  []byte("hello world"),
+		"c.txt":      []byte("hello there"),
+		"big1":       big,
+		"sub/big2":   big,
+		"big3":       near,
+		"empty1":     nil,
+		"empty2":     nil,
+		"single.txt": []byte("only one of me"),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Link(filepath.Join(dir, "big1"), filepath.Join(dir, "big1.link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(filepath.Join(dir, "single.txt"), filepath.Join(dir, "single.link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "a.txt"), filepath.Join(dir, "a.symlink")); err != nil {
+		t.Fatal(err)
+	}
+
+	groups, err := FindDuplicates(context.Background(), dir, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([][]string, 0, len(groups))
+	for _, group := range groups {
+		names := make([]string, 0, len(group.Files))
+		for _, file := range group.Files {
+			rel, _ := filepath.Rel(dir, file.Path)
+			names = append(names, filepath.ToSlash(rel))
+		}
+		got = append(got, names)
+	}
+	want := [][]string{{"big1", "big1.link", "sub/big2"}, {"a.txt", "sub/b.txt"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("groups = %q, want %q", got, want)
+	}
+	if wasted := groups[0].Wasted(); wasted != int64(len(big)) {
+		t.Errorf("big group wastes %d, want %d (hard links count once)", wasted, len(big))
+	}
+	if wasted := groups[1].Wasted(); wasted != 11 {
+		t.Errorf("small group wastes %d, want 11", wasted)
+	}
+
+	groups, err = FindDuplicates(context.Background(), dir, 100, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || groups[0].Size != int64(len(big)) {
+		t.Errorf("with a 100 byte minimum got %d groups, want only the big one", len(groups))
+	}
+}
@@ -1,9 +1,11 @@
 package finder
 
 import (
-	"bufio"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -19,24 +21,22 @@ type Result struct {
 	Size     string
 	Modified string
 	IsDir    bool
+	Bytes    int64
+	ModTime  time.Time
 }
 
-type ContentResult struct {
-	Path    string
-	Line    int
-	Content string
-}
+var ErrMaxResults = errors.New("finder: max results reached")
 
-func FindByName(root, keyword string, exact bool) ([]Result, error) {
-	return FindByNameWithExt(root, keyword, "", exact)
+func FindByName(ctx context.Context, root, keyword string, exact bool) ([]Result, error) {
+	return FindByNameWithExt(ctx, root, keyword, "", exact)
 }
 
-func FindByNameWithExt(root, keyword, ext string, exact bool) ([]Result, error) {
+func FindByNameWithExt(ctx context.Context, root, keyword, ext string, exact bool) ([]Result, error) {
 	ext = strings.TrimSpace(ext)
 	if ext != "" && !strings.HasPrefix(ext, ".") {
 		ext = "." + ext
 	}
-	return walkFiles(root, func(path string, info os.FileInfo) (bool, error) {
+	return walkFiles(ctx, root, func(_ context.Context, path string, info os.FileInfo) (bool, error) {
 		if info.IsDir() && ext != "" {
 			return false, nil
 		}
@@ -50,12 +50,12 @@ func FindByNameWithExt(root, keyword, ext string, exact bool) ([]Result, error)
 	})
 }
 
-func FindByExt(root, ext string) ([]Result, error) {
+func FindByExt(ctx context.Context, root, ext string) ([]Result, error) {
 	ext = strings.TrimSpace(ext)
 	if ext != "" && !strings.HasPrefix(ext, ".") {
 		ext = "." + ext
 	}
-	return walkFiles(root, func(path string, info os.FileInfo) (bool, error) {
+	return walkFiles(ctx, root, func(_ context.Context, path string, info os.FileInfo) (bool, error) {
 		if info.IsDir() {
 			return false, nil
 		}
@@ -63,50 +63,12 @@ func FindByExt(root, ext string) ([]Result, error) {
 	})
 }
 
-func FindByContent(root, query string) ([]ContentResult, error) {
-	return FindByContentWithExt(root, query, "")
-}
-
-func FindByContentWithExt(root, query, ext string) ([]ContentResult, error) {
-	cfg, err := config.Load()
-	if err != nil {
-		return nil, err
-	}
-	results := make([]ContentResult, 0)
-	count := 0
-	forEachFile(root, cfg.IgnoreDirs, func(path string, info os.FileInfo) error {
-		count++
-		if count%200 == 0 {
-			fmt.Println("  " + voice.Linef("searching_file", path))
-		}
-		if ext != "" && !strings.EqualFold(filepath.Ext(info.Name()), ext) {
-			return nil
-		}
-		file, err := os.Open(path)
-		if err != nil {
-			return nil
-		}
-		defer file.Close()
-		scanner := bufio.NewScanner(file)
-		lineNum := 0
-		for scanner.Scan() {
-			lineNum++
-			text := scanner.Text()
-			if strings.Contains(text, query) {
-				results = append(results, ContentResult{Path: path, Line: lineNum, Content: strings.TrimSpace(text)})
-			}
-		}
-		return nil
-	})
-	return results, nil
-}
-
-func FindGlobal(query, ext string) ([]ContentResult, error) {
+func FindGlobal(ctx context.Context, query, ext string) ([]ContentResult, error) {
 	path, err := GlobalSearchPath()
 	if err != nil {
 		return nil, err
 	}
-	return FindByContentWithExt(path, query, ext)
+	return FindByContentWithExt(ctx, path, query, ext)
 }
 
 func GlobalSearchPath() (string, error) {
@@ -117,12 +79,12 @@ func GlobalSearchPath() (string, error) {
 	return home, nil
 }
 
-func FindBySize(root, condition, sizeInput string) ([]Result, error) {
-	threshold, err := parseSize(sizeInput)
+func FindBySize(ctx context.Context, root, condition, sizeInput string) ([]Result, error) {
+	threshold, err := ParseSize(sizeInput)
 	if err != nil {
 		return nil, err
 	}
-	return walkFiles(root, func(path string, info os.FileInfo) (bool, error) {
+	return walkFiles(ctx, root, func(_ context.Context, path string, info os.FileInfo) (bool, error) {
 		size := info.Size()
 		switch condition {
 		case "1":
@@ -137,13 +99,13 @@ func FindBySize(root, condition, sizeInput string) ([]Result, error) {
 	})
 }
 
-func FindByTime(root, condition, daysInput string) ([]Result, error) {
+func FindByTime(ctx context.Context, root, condition, daysInput string) ([]Result, error) {
 	days, err := strconv.Atoi(daysInput)
 	if err != nil {
 		return nil, errors.New(voice.Line("invalid_days"))
 	}
 	cutoff := time.Now().AddDate(0, 0, -days)
-	return walkFiles(root, func(path string, info os.FileInfo) (bool, error) {
+	return walkFiles(ctx, root, func(_ context.Context, path string, info os.FileInfo) (bool, error) {
 		mod := info.ModTime()
 		switch condition {
 		case "1":
@@ -156,104 +118,220 @@ func FindByTime(root, condition, daysInput string) ([]Result, error) {
 	})
 }
 
-func walkFiles(root string, matcher func(path string, info os.FileInfo) (bool, error)) ([]Result, error) {
-	cfg, err := config.Load()
+func walkFiles(ctx context.Context, root string, matcher func(ctx context.Context, path string, info os.FileInfo) (bool, error)) ([]Result, error) {
+	opts, err := loadWalkOptions()
 	if err != nil {
 		return nil, err
 	}
-	results := make([]Result, 0)
-	forEachDirThenFiles(root, cfg.IgnoreDirs, func(path string, info os.FileInfo) error {
-		match, err := matcher(path, info)
+	ctx, cancel := opts.budget(ctx)
+	defer cancel()
+	dirs := make([]Result, 0)
+	files := make([]Result, 0)
+	add := func(path string, info os.FileInfo) error {
+		if match, err := matcher(ctx, path, info); err != nil || !match {
+			return nil
+		}
+		if info.IsDir() {
+			dirs = append(dirs, newResult(path, info))
+		} else {
+			files = append(files, newResult(path, info))
+		}
+		if opts.maxResults > 0 && len(dirs)+len(files) >= opts.maxResults {
+			return ErrMaxResults
+		}
+		return nil
+	}
+	err = walkCandidates(ctx, root, opts, func(path string, entry os.DirEntry) error {
+		info, err := entry.Info()
 		if err != nil {
+			return nil
+		}
+		if err := add(path, info); err != nil {
 			return err
 		}
-		if match {
-			results = append(results, Result{
-				Path:     path,
-				Size:     formatSize(info.Size()),
-				Modified: info.ModTime().Format("2006-01-02 15:04"),
-				IsDir:    info.IsDir(),
+		if opts.searchArchives && info.Mode().IsRegular() && IsArchive(path) {
+			err := walkArchive(ctx, path, func(inner string, member os.FileInfo, _ io.Reader) error {
+				return add(path+ArchiveSeparator+inner, member)
 			})
+			if errors.Is(err, ErrMaxResults) || ctx.Err() != nil {
+				return err
+			}
 		}
 		return nil
 	})
-	return results, nil
+	return append(dirs, files...), err
+}
+
+func newResult(path string, info os.FileInfo) Result {
+	return Result{
+		Path:     path,
+		Size:     FormatSize(info.Size()),
+		Modified: info.ModTime().Format("2006-01-02 15:04"),
+		IsDir:    info.IsDir(),
+		Bytes:    info.Size(),
+		ModTime:  info.ModTime(),
+	}
+}
+
+type walkOptions struct {
+	ignoreDirs     []string
+	includeHidden  bool
+	respectIgnore  bool
+	useIndex       bool
+	searchArchives bool
+	maxDepth       int
+	maxResults     int
+	timeBudget     time.Duration
+	skipLinks      bool
+}
+
+func loadWalkOptions() (walkOptions, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return walkOptions{}, err
+	}
+	return walkOptions{
+		ignoreDirs:     cfg.IgnoreDirs,
+		includeHidden:  cfg.SearchHidden,
+		respectIgnore:  cfg.RespectIgnore,
+		useIndex:       cfg.UseIndex,
+		searchArchives: cfg.SearchArchives,
+		maxDepth:       cfg.SearchMaxDepth,
+		maxResults:     cfg.SearchMaxResults,
+		timeBudget:     time.Duration(cfg.SearchTimeout) * time.Second,
+	}, nil
+}
+
+func (o walkOptions) budget(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.timeBudget <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, o.timeBudget)
 }
 
-func forEachFile(root string, ignore []string, handle func(path string, info os.FileInfo) error) {
-	_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+type treeFilter struct {
+	opts    walkOptions
+	ignores *ignoreSet
+}
+
+func newTreeFilter(root string, opts walkOptions) *treeFilter {
+	filter := &treeFilter{opts: opts}
+	if opts.respectIgnore {
+		filter.ignores = newIgnoreSet(root)
+	}
+	return filter
+}
+
+func (f *treeFilter) skipDir(path, name string) bool {
+	if name == ".git" || !f.opts.includeHidden && strings.HasPrefix(name, ".") {
+		return true
+	}
+	for _, skip := range f.opts.ignoreDirs {
+		if name == skip {
+			return true
+		}
+	}
+	return f.ignores != nil && f.ignores.Ignored(path, true)
+}
+
+func (f *treeFilter) skipFile(path string) bool {
+	return f.ignores != nil && f.ignores.Ignored(path, false)
+}
+
+func (f *treeFilter) enter(dir string) {
+	if f.ignores != nil {
+		f.ignores.load(dir)
+	}
+}
+
+func walkTree(ctx context.Context, root string, opts walkOptions, handle func(path string, entry os.DirEntry) error) error {
+	root = filepath.Clean(root)
+	filter := newTreeFilter(root, opts)
+	return filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
-		if info.IsDir() {
-			name := info.Name()
-			if strings.HasPrefix(name, ".") {
-				if path != root {
-					return filepath.SkipDir
-				}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if path == root {
+			if entry.IsDir() {
+				return nil
 			}
-			for _, skip := range ignore {
-				if name == skip {
-					return filepath.SkipDir
-				}
+			return handle(path, entry)
+		}
+		if entry.IsDir() {
+			if filter.skipDir(path, entry.Name()) {
+				return filepath.SkipDir
 			}
+			filter.enter(path)
+			return handle(path, entry)
+		}
+		if filter.skipFile(path) {
 			return nil
 		}
-		_ = handle(path, info)
-		return nil
+		return handle(path, entry)
 	})
 }
 
-func forEachDirThenFiles(root string, ignore []string, handle func(path string, info os.FileInfo) error) {
-	type searchEntry struct {
-		path string
-		info os.FileInfo
+func walkCandidates(ctx context.Context, root string, opts walkOptions, handle func(path string, entry os.DirEntry) error) error {
+	if opts.maxDepth > 0 {
+		handle = limitDepth(root, opts.maxDepth, handle)
+	}
+	if ok, err := indexedWalk(ctx, root, opts, handle); ok {
+		return err
 	}
-	var dirs []searchEntry
-	var files []searchEntry
+	return walkTree(ctx, root, opts, handle)
+}
 
-	_ = filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		if entry.IsDir() {
-			name := entry.Name()
-			if strings.HasPrefix(name, ".") {
-				if path != root {
-					return filepath.SkipDir
-				}
-			}
-			for _, skip := range ignore {
-				if name == skip {
-					return filepath.SkipDir
-				}
-			}
-			if path != root {
-				info, err := entry.Info()
-				if err == nil {
-					dirs = append(dirs, searchEntry{path: path, info: info})
-				}
+func forEachFile(ctx context.Context, root string, opts walkOptions, handle func(path string, entry os.DirEntry) error) error {
+	return walkCandidates(ctx, root, opts, func(path string, entry os.DirEntry) error {
+		if entry.Type()&os.ModeSymlink != 0 && !opts.skipLinks {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil
 			}
+			entry = fs.FileInfoToDirEntry(info)
+		}
+		if !entry.Type().IsRegular() {
 			return nil
 		}
-		info, err := entry.Info()
+		return handle(path, entry)
+	})
+}
+
+func limitDepth(root string, maxDepth int, handle func(path string, entry os.DirEntry) error) func(path string, entry os.DirEntry) error {
+	root = filepath.Clean(root)
+	return func(path string, entry os.DirEntry) error {
+		rel, err := filepath.Rel(root, path)
 		if err != nil {
+			return handle(path, entry)
+		}
+		depth := 0
+		if rel != "." {
+			depth = strings.Count(filepath.ToSlash(rel), "/") + 1
+		}
+		if depth > maxDepth {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
 			return nil
 		}
-		files = append(files, searchEntry{path: path, info: info})
+		if err := handle(path, entry); err != nil {
+			return err
+		}
+		if entry.IsDir() && depth == maxDepth {
+			return filepath.SkipDir
+		}
 		return nil
-	})
-
-	for _, item := range dirs {
-		_ = handle(item.path, item.info)
-	}
-	for _, item := range files {
-		_ = handle(item.path, item.info)
 	}
 }
 
-func parseSize(input string) (int64, error) {
+func ParseSize(input string) (int64, error) {
 	input = strings.TrimSpace(strings.ToUpper(input))
+	if len(input) > 2 && strings.HasSuffix(input, "B") && strings.ContainsAny(input[len(input)-2:len(input)-1], "KMG") {
+		input = input[:len(input)-1]
+	}
 	if input == "" {
 		return 0, errors.New(voice.Line("invalid_size"))
 	}
@@ -278,7 +356,7 @@ func parseSize(input string) (int64, error) {
 	return int64(value * float64(multiplier)), nil
 }
 
-func formatSize(size int64) string {
+func FormatSize(size int64) string {
 	if size > 1024*1024*1024 {
 		return fmt.Sprintf("%.1fG", float64(size)/1024/1024/1024)
 	}
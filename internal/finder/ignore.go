@@ -0,0 +1,236 @@
+package finder
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var IgnoreFiles = []string{".gitignore", ".ignore", ".sakiboxignore"}
+
+type ignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+type ignoreSet struct {
+	root  string
+	outer []outerRules
+	dirs  map[string][]ignoreRule
+}
+
+type outerRules struct {
+	prefix string
+	rules  []ignoreRule
+}
+
+func newIgnoreSet(root string) *ignoreSet {
+	set := &ignoreSet{root: filepath.Clean(root), dirs: make(map[string][]ignoreRule)}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		set.load(set.root)
+		return set
+	}
+	gitRoot := ""
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if isDir(filepath.Join(dir, ".git")) {
+			gitRoot = dir
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	if gitRoot != "" && gitRoot != abs {
+		ancestors := make([]string, 0)
+		for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+			ancestors = append(ancestors, dir)
+			if dir == gitRoot {
+				break
+			}
+		}
+		for i := len(ancestors) - 1; i >= 0; i-- {
+			rules := readIgnoreRules(ancestors[i], ancestors[i] == gitRoot)
+			if len(rules) == 0 {
+				continue
+			}
+			prefix, err := filepath.Rel(ancestors[i], abs)
+			if err != nil {
+				continue
+			}
+			set.outer = append(set.outer, outerRules{prefix: filepath.ToSlash(prefix), rules: rules})
+		}
+	}
+	if rules := readIgnoreRules(set.root, gitRoot == abs); len(rules) > 0 {
+		set.dirs[set.root] = rules
+	}
+	return set
+}
+
+func (s *ignoreSet) load(dir string) {
+	if rules := readIgnoreRules(dir, false); len(rules) > 0 {
+		s.dirs[dir] = rules
+	}
+}
+
+func readIgnoreRules(dir string, gitRoot bool) []ignoreRule {
+	paths := make([]string, 0, len(IgnoreFiles)+1)
+	if gitRoot {
+		paths = append(paths, filepath.Join(dir, ".git", "info", "exclude"))
+	}
+	for _, name := range IgnoreFiles {
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	rules := make([]ignoreRule, 0)
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if rule, ok := parseIgnoreRule(scanner.Text()); ok {
+				rules = append(rules, rule)
+			}
+		}
+		file.Close()
+	}
+	return rules
+}
+
+func (s *ignoreSet) Ignored(path string, dir bool) bool {
+	ignored := false
+	if len(s.outer) > 0 {
+		if rel, err := filepath.Rel(s.root, path); err == nil {
+			rel = filepath.ToSlash(rel)
+			for _, outer := range s.outer {
+				ignored = matchRules(outer.rules, outer.prefix+"/"+rel, dir, ignored)
+			}
+		}
+	}
+	if len(s.dirs) == 0 {
+		return ignored
+	}
+	bases := make([]string, 0)
+	for base := filepath.Dir(path); ; base = filepath.Dir(base) {
+		if _, ok := s.dirs[base]; ok {
+			bases = append(bases, base)
+		}
+		if base == s.root || filepath.Dir(base) == base {
+			break
+		}
+	}
+	for i := len(bases) - 1; i >= 0; i-- {
+		rel, err := filepath.Rel(bases[i], path)
+		if err != nil {
+			continue
+		}
+		ignored = matchRules(s.dirs[bases[i]], filepath.ToSlash(rel), dir, ignored)
+	}
+	return ignored
+}
+
+func matchRules(rules []ignoreRule, rel string, dir, ignored bool) bool {
+	for _, rule := range rules {
+		if rule.dirOnly && !dir {
+			continue
+		}
+		if rule.re.MatchString(rel) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+func parseIgnoreRule(line string) (ignoreRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	if !anchored {
+		pattern.WriteString("(?:.*/)?")
+	}
+	pattern.WriteString(globToRegexp(line))
+	pattern.WriteString("$")
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	rule.re = re
+	return rule, true
+}
+
+func globToRegexp(glob string) string {
+	var out strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == '*' && strings.HasPrefix(glob[i:], "**"):
+			atStart := i == 0 || glob[i-1] == '/'
+			rest := glob[i+2:]
+			switch {
+			case atStart && strings.HasPrefix(rest, "/"):
+				out.WriteString("(?:.*/)?")
+				i += 2
+			case atStart && rest == "":
+				out.WriteString(".*")
+				i++
+			default:
+				out.WriteString("[^/]*")
+				i++
+			}
+		case c == '*':
+			out.WriteString("[^/]*")
+		case c == '?':
+			out.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				out.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			out.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			out.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			out.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return out.String()
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,78 @@
+package finder
+
+import "testing"
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob string
+		want string
+	}{
+		{"*.log", `[^/]*\.log`},
+		{"file?.txt", `file[^/]\.txt`},
+		{"**/foo", `(?:.*/)?foo`},
+		{"a/**/b", `a/(?:.*/)?b`},
+		{"a/**", `a/.*`},
+		{"a**b", `a[^/]*b`},
+		{"[abc].go", `[abc]\.go`},
+		{"[!a]bc", `[^a]bc`},
+		{`[a\]`, `[a\\]`},
+		{"x[", `x\[`},
+		{`\*.md`, `\*\.md`},
+		{"a+b(1)", `a\+b\(1\)`},
+	}
+	for _, tt := range tests {
+		if got := globToRegexp(tt.glob); got != tt.want {
+			t.Errorf("globToRegexp(%q) = %q, want %q", tt.glob, got, tt.want)
+		}
+	}
+}
+
+func TestIgnoreRules(t *testing.T) {
+	tests := []struct {
+		lines []string
+		path  string
+		dir   bool
+		want  bool
+	}{
+		{[]string{"*.log"}, "app.log", false, true},
+		{[]string{"*.log"}, "logs/app.log", false, true},
+		{[]string{"*.log"}, "app.log.txt", false, false},
+		{[]string{"/build"}, "build", true, true},
+		{[]string{"/build"}, "src/build", true, false},
+		{[]string{"build/"}, "build", true, true},
+		{[]string{"build/"}, "build", false, false},
+		{[]string{"build/"}, "src/build", true, true},
+		{[]string{"doc/*.txt"}, "doc/a.txt", false, true},
+		{[]string{"doc/*.txt"}, "doc/x/a.txt", false, false},
+		{[]string{"doc/*.txt"}, "src/doc/a.txt", false, false},
+		{[]string{"**/foo"}, "foo", false, true},
+		{[]string{"**/foo"}, "a/b/foo", false, true},
+		{[]string{"a/**"}, "a/b/c", false, true},
+		{[]string{"a/**"}, "a", true, false},
+		{[]string{"a/**/b"}, "a/b", false, true},
+		{[]string{"a/**/b"}, "a/x/y/b", false, true},
+		{[]string{"file?.txt"}, "file1.txt", false, true},
+		{[]string{"file?.txt"}, "file10.txt", false, false},
+		{[]string{"[!a]bc"}, "xbc", false, true},
+		{[]string{"[!a]bc"}, "abc", false, false},
+		{[]string{`\#notes`}, "#notes", false, true},
+		{[]string{`\!important`}, "!important", false, true},
+		{[]string{`trailing\ `}, "trailing ", false, true},
+		{[]string{"spaces   "}, "spaces", false, true},
+		{[]string{"*.log", "!keep.log"}, "keep.log", false, false},
+		{[]string{"*.log", "!keep.log"}, "drop.log", false, true},
+		{[]string{"!keep.log", "*.log"}, "keep.log", false, true},
+		{[]string{"# comment", ""}, "# comment", false, false},
+	}
+	for _, tt := range tests {
+		rules := make([]ignoreRule, 0, len(tt.lines))
+		for _, line := range tt.lines {
+			if rule, ok := parseIgnoreRule(line); ok {
+				rules = append(rules, rule)
+			}
+		}
+		if got := matchRules(rules, tt.path, tt.dir, false); got != tt.want {
+			t.Errorf("rules %q on %q (dir %v) = %v, want %v", tt.lines, tt.path, tt.dir, got, tt.want)
+		}
+	}
+}
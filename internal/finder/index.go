@@ -0,0 +1,400 @@
+package finder
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"sakibox/internal/storage"
+)
+
+const (
+	indexName    = "index.gob"
+	indexVersion = 1
+	indexMaxAge  = 24 * time.Hour
+)
+
+type IndexStats struct {
+	Root  string
+	Dirs  int
+	Files int
+	Built time.Time
+}
+
+type fileIndex struct {
+	Version   int
+	Root      string
+	Signature string
+	Built     time.Time
+	Dirs      map[string]*indexDir
+}
+
+type indexDir struct {
+	ModTime int64
+	Entries []indexEntry
+}
+
+type indexEntry struct {
+	Name    string
+	Size    int64
+	ModTime int64
+	Mode    uint32
+	Inode   uint64
+}
+
+type indexDirEntry struct {
+	path  string
+	entry *indexEntry
+}
+
+func (e indexDirEntry) Name() string               { return e.entry.Name }
+func (e indexDirEntry) IsDir() bool                { return e.Type().IsDir() }
+func (e indexDirEntry) Type() fs.FileMode          { return fs.FileMode(e.entry.Mode).Type() }
+func (e indexDirEntry) Info() (fs.FileInfo, error) { return os.Lstat(e.path) }
+
+var indexCache struct {
+	sync.Mutex
+	index   *fileIndex
+	modTime time.Time
+}
+
+func IndexInfo() (IndexStats, bool, error) {
+	indexCache.Lock()
+	defer indexCache.Unlock()
+	index, err := loadIndex()
+	if err != nil || index == nil {
+		return IndexStats{}, false, err
+	}
+	return index.stats(), true, nil
+}
+
+func RebuildIndex(ctx context.Context) (IndexStats, error) {
+	opts, err := loadWalkOptions()
+	if err != nil {
+		return IndexStats{}, err
+	}
+	root, err := GlobalSearchPath()
+	if err != nil {
+		return IndexStats{}, err
+	}
+	indexCache.Lock()
+	defer indexCache.Unlock()
+	index := newFileIndex(root, opts)
+	if _, err := index.refresh(ctx, root, opts); err != nil {
+		return IndexStats{}, err
+	}
+	if err := saveIndex(index); err != nil {
+		return IndexStats{}, err
+	}
+	return index.stats(), nil
+}
+
+func newFileIndex(root string, opts walkOptions) *fileIndex {
+	return &fileIndex{
+		Version:   indexVersion,
+		Root:      root,
+		Signature: opts.signature(),
+		Built:     time.Now(),
+		Dirs:      make(map[string]*indexDir),
+	}
+}
+
+func (i *fileIndex) stats() IndexStats {
+	stats := IndexStats{Root: i.Root, Dirs: len(i.Dirs), Built: i.Built}
+	for _, dir := range i.Dirs {
+		for _, entry := range dir.Entries {
+			if !fs.FileMode(entry.Mode).IsDir() {
+				stats.Files++
+			}
+		}
+	}
+	return stats
+}
+
+func (o walkOptions) signature() string {
+	return fmt.Sprintf("hidden=%t ignore=%t dirs=%s", o.includeHidden, o.respectIgnore, strings.Join(o.ignoreDirs, ","))
+}
+
+func indexedWalk(ctx context.Context, root string, opts walkOptions, handle func(path string, entry os.DirEntry) error) (bool, error) {
+	if !opts.useIndex {
+		return false, nil
+	}
+	home, err := GlobalSearchPath()
+	if err != nil {
+		return false, nil
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return false, nil
+	}
+	rel, err := filepath.Rel(home, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false, nil
+	}
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		return false, nil
+	}
+	rel = filepath.ToSlash(rel)
+	subtree, ok, err := indexSubtree(ctx, home, abs, rel, opts)
+	if !ok || err != nil {
+		return ok, err
+	}
+	return true, subtree.walk(ctx, rel, filepath.Clean(root), handle)
+}
+
+func indexSubtree(ctx context.Context, home, abs, rel string, opts walkOptions) (*fileIndex, bool, error) {
+	indexCache.Lock()
+	defer indexCache.Unlock()
+	index, err := loadIndex()
+	if err != nil {
+		return nil, false, nil
+	}
+	if index == nil || index.Root != home || index.Signature != opts.signature() || time.Since(index.Built) > indexMaxAge {
+		if rel != "." {
+			return nil, false, nil
+		}
+		index = newFileIndex(home, opts)
+	} else if index.Dirs[rel] == nil {
+		return nil, false, nil
+	}
+	changed, err := index.refresh(ctx, abs, opts)
+	if err != nil {
+		return nil, true, err
+	}
+	if changed {
+		_ = saveIndex(index)
+	}
+	subtree := &fileIndex{Root: index.Root, Dirs: make(map[string]*indexDir)}
+	for key, dir := range index.Dirs {
+		if rel == "." || key == rel || strings.HasPrefix(key, rel+"/") {
+			subtree.Dirs[key] = dir
+		}
+	}
+	return subtree, true, nil
+}
+
+func (i *fileIndex) refresh(ctx context.Context, start string, opts walkOptions) (bool, error) {
+	r := &indexRefresh{index: i, filter: newTreeFilter(i.Root, opts), loaded: map[string]bool{i.Root: true}}
+	rel, err := filepath.Rel(i.Root, start)
+	if err != nil {
+		return false, err
+	}
+	if err := r.dir(ctx, filepath.ToSlash(rel)); err != nil {
+		return false, err
+	}
+	return r.changed, nil
+}
+
+type indexRefresh struct {
+	index   *fileIndex
+	filter  *treeFilter
+	loaded  map[string]bool
+	changed bool
+}
+
+func (r *indexRefresh) abs(rel string) string {
+	if rel == "." {
+		return r.index.Root
+	}
+	return filepath.Join(r.index.Root, filepath.FromSlash(rel))
+}
+
+func (r *indexRefresh) dir(ctx context.Context, rel string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path := r.abs(rel)
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		r.drop(rel)
+		return nil
+	}
+	cached := r.index.Dirs[rel]
+	if cached == nil || cached.ModTime != info.ModTime().UnixNano() {
+		cached = r.scan(path, info)
+		r.index.Dirs[rel] = cached
+		r.changed = true
+	}
+	for _, entry := range cached.Entries {
+		if fs.FileMode(entry.Mode).IsDir() {
+			if err := r.dir(ctx, joinRel(rel, entry.Name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *indexRefresh) scan(path string, info os.FileInfo) *indexDir {
+	r.enter(path)
+	scanned := &indexDir{ModTime: info.ModTime().UnixNano()}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return scanned
+	}
+	old := make(map[string]bool)
+	if cached := r.index.Dirs[r.rel(path)]; cached != nil {
+		for _, entry := range cached.Entries {
+			if fs.FileMode(entry.Mode).IsDir() {
+				old[entry.Name] = true
+			}
+		}
+	}
+	for _, entry := range entries {
+		child := filepath.Join(path, entry.Name())
+		if entry.IsDir() && r.filter.skipDir(child, entry.Name()) || !entry.IsDir() && r.filter.skipFile(child) {
+			continue
+		}
+		childInfo, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if childInfo.IsDir() {
+			delete(old, entry.Name())
+		}
+		scanned.Entries = append(scanned.Entries, indexEntry{
+			Name:    entry.Name(),
+			Size:    childInfo.Size(),
+			ModTime: childInfo.ModTime().UnixNano(),
+			Mode:    uint32(childInfo.Mode()),
+			Inode:   inode(childInfo),
+		})
+	}
+	for name := range old {
+		r.drop(joinRel(r.rel(path), name))
+	}
+	return scanned
+}
+
+func (r *indexRefresh) rel(path string) string {
+	rel, err := filepath.Rel(r.index.Root, path)
+	if err != nil {
+		return "."
+	}
+	return filepath.ToSlash(rel)
+}
+
+func (r *indexRefresh) enter(path string) {
+	pending := make([]string, 0)
+	for dir := path; !r.loaded[dir]; dir = filepath.Dir(dir) {
+		pending = append(pending, dir)
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	for i := len(pending) - 1; i >= 0; i-- {
+		r.filter.enter(pending[i])
+		r.loaded[pending[i]] = true
+	}
+}
+
+func (r *indexRefresh) drop(rel string) {
+	if _, ok := r.index.Dirs[rel]; !ok {
+		return
+	}
+	r.changed = true
+	prefix := rel + "/"
+	for key := range r.index.Dirs {
+		if key == rel || strings.HasPrefix(key, prefix) {
+			delete(r.index.Dirs, key)
+		}
+	}
+}
+
+func joinRel(dir, name string) string {
+	if dir == "." {
+		return name
+	}
+	return dir + "/" + name
+}
+
+func (i *fileIndex) walk(ctx context.Context, rel, root string, handle func(path string, entry os.DirEntry) error) error {
+	dir := i.Dirs[rel]
+	if dir == nil {
+		return nil
+	}
+	for n := range dir.Entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entry := indexDirEntry{path: filepath.Join(root, dir.Entries[n].Name), entry: &dir.Entries[n]}
+		path := entry.path
+		if err := handle(path, entry); err != nil {
+			if errors.Is(err, filepath.SkipDir) && entry.IsDir() {
+				continue
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if err := i.walk(ctx, joinRel(rel, entry.Name()), path, handle); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func loadIndex() (*fileIndex, error) {
+	path, err := storage.Path(indexName)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		indexCache.index = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if indexCache.index != nil && indexCache.modTime.Equal(info.ModTime()) {
+		return indexCache.index, nil
+	}
+	unlock, err := storage.Lock(path, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	index := &fileIndex{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(index); err != nil || index.Version != indexVersion || index.Dirs == nil {
+		return nil, nil
+	}
+	indexCache.index, indexCache.modTime = index, info.ModTime()
+	return index, nil
+}
+
+func saveIndex(index *fileIndex) error {
+	path, err := storage.Path(indexName)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(index); err != nil {
+		return err
+	}
+	unlock, err := storage.Lock(path, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := storage.WriteFileAtomic(path, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	indexCache.index = index
+	if info, err := os.Stat(path); err == nil {
+		indexCache.modTime = info.ModTime()
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package finder
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"sakibox/internal/voice"
+)
+
+type PreviewLine struct {
+	Line int
+	Text string
+}
+
+var errPreviewFound = errors.New("preview found")
+
+func Preview(result string, line, limit int) ([]PreviewLine, error) {
+	archive, inner, inArchive := SplitArchivePath(result)
+	if inArchive {
+		var lines []PreviewLine
+		err := walkArchive(context.Background(), archive, func(name string, _ fs.FileInfo, r io.Reader) error {
+			if name != inner {
+				return nil
+			}
+			var err error
+			if lines, err = previewLines(r, line, limit); err != nil {
+				return err
+			}
+			return errPreviewFound
+		})
+		if errors.Is(err, errPreviewFound) {
+			err = nil
+		}
+		return lines, err
+	}
+
+	info, err := os.Stat(result)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		entries, err := os.ReadDir(result)
+		if err != nil {
+			return nil, err
+		}
+		lines := make([]PreviewLine, 0, min(len(entries), limit))
+		for _, entry := range entries[:min(len(entries), limit)] {
+			name := entry.Name()
+			if entry.IsDir() {
+				name += "/"
+			}
+			lines = append(lines, PreviewLine{Text: name})
+		}
+		return lines, nil
+	}
+	file, err := os.Open(result)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return previewLines(file, line, limit)
+}
+
+func previewLines(r io.Reader, line, limit int) ([]PreviewLine, error) {
+	reader := bufio.NewReaderSize(r, binarySniffSize)
+	head, _ := reader.Peek(binarySniffSize)
+	if bytes.IndexByte(head, 0) >= 0 {
+		return nil, errors.New(voice.Line("finder_preview_binary"))
+	}
+	first := 1
+	if line > 0 {
+		first = max(line-limit/2, 1)
+	}
+	lines := make([]PreviewLine, 0, limit)
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), readChunkSize)
+	for num := 1; len(lines) < limit && scanner.Scan(); num++ {
+		if num >= first {
+			lines = append(lines, PreviewLine{Line: num, Text: strings.TrimRight(scanner.Text(), "\r")})
+		}
+	}
+	return lines, nil
+}
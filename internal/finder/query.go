@@ -0,0 +1,498 @@
+package finder
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sakibox/internal/voice"
+)
+
+type Query struct {
+	root node
+	dirs bool
+}
+
+type node interface {
+	match(c *candidate) bool
+	cost() int
+}
+
+type candidate struct {
+	ctx  context.Context
+	path string
+	rel  string
+	info os.FileInfo
+}
+
+type andNode []node
+type orNode []node
+
+type notNode struct {
+	inner node
+}
+
+type predicate struct {
+	weight int
+	test   func(c *candidate) bool
+}
+
+func (n andNode) match(c *candidate) bool {
+	for _, child := range n {
+		if !child.match(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n andNode) cost() int {
+	return maxCost(n)
+}
+
+func (n orNode) match(c *candidate) bool {
+	for _, child := range n {
+		if child.match(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n orNode) cost() int {
+	return maxCost(n)
+}
+
+func (n notNode) match(c *candidate) bool {
+	return !n.inner.match(c)
+}
+
+func (n notNode) cost() int {
+	return n.inner.cost()
+}
+
+func (p predicate) match(c *candidate) bool {
+	return p.test(c)
+}
+
+func (p predicate) cost() int {
+	return p.weight
+}
+
+func maxCost(nodes []node) int {
+	cost := 0
+	for _, child := range nodes {
+		cost = max(cost, child.cost())
+	}
+	return cost
+}
+
+func FindByQuery(ctx context.Context, root, expression string) ([]Result, error) {
+	query, err := ParseQuery(expression)
+	if err != nil {
+		return nil, err
+	}
+	return FindByQueryMatcher(ctx, root, query)
+}
+
+func FindByQueryMatcher(ctx context.Context, root string, query *Query) ([]Result, error) {
+	return walkFiles(ctx, root, func(ctx context.Context, path string, info os.FileInfo) (bool, error) {
+		return query.Match(ctx, root, path, info), nil
+	})
+}
+
+func (q *Query) Match(ctx context.Context, root, path string, info os.FileInfo) bool {
+	if info.IsDir() && !q.dirs {
+		return false
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		rel = filepath.Base(path)
+	}
+	return q.root.match(&candidate{ctx: ctx, path: path, rel: filepath.ToSlash(rel), info: info})
+}
+
+func ParseQuery(expression string) (*Query, error) {
+	tokens, err := lexQuery(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, queryError(voice.Line("finder_query_empty"))
+	}
+	p := &queryParser{tokens: tokens, query: &Query{}}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, queryError(voice.Linef("finder_query_unexpected", p.tokens[p.pos]))
+	}
+	p.query.root = root
+	return p.query, nil
+}
+
+func queryError(message string) error {
+	return errors.New(voice.Linef("finder_query_invalid", message))
+}
+
+func lexQuery(input string) ([]string, error) {
+	tokens := make([]string, 0)
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			i++
+			continue
+		case r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+			i++
+			continue
+		}
+		var token strings.Builder
+		for i < len(runes) && runes[i] != ' ' && runes[i] != '\t' && runes[i] != '\n' && runes[i] != ')' {
+			switch {
+			case runes[i] == '"':
+				end := closing(runes, i+1, '"')
+				if end < 0 {
+					return nil, queryError(voice.Line("finder_query_unclosed"))
+				}
+				token.WriteString(strings.ReplaceAll(string(runes[i+1:end]), `\"`, `"`))
+				i = end + 1
+			case runes[i] == '/' && strings.HasSuffix(token.String(), ":"):
+				end := closing(runes, i+1, '/')
+				if end < 0 {
+					return nil, queryError(voice.Line("finder_query_unclosed"))
+				}
+				token.WriteString(string(runes[i : end+1]))
+				i = end + 1
+			default:
+				token.WriteRune(runes[i])
+				i++
+			}
+		}
+		tokens = append(tokens, token.String())
+	}
+	return tokens, nil
+}
+
+func closing(runes []rune, start int, delim rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == '\\' {
+			i++
+			continue
+		}
+		if runes[i] == delim {
+			return i
+		}
+	}
+	return -1
+}
+
+type queryParser struct {
+	tokens []string
+	pos    int
+	query  *Query
+}
+
+func (p *queryParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *queryParser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	nodes := []node{left}
+	for {
+		token := p.peek()
+		if !strings.EqualFold(token, "OR") && token != "|" {
+			break
+		}
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, right)
+	}
+	if len(nodes) == 1 {
+		return left, nil
+	}
+	return orNode(nodes), nil
+}
+
+func (p *queryParser) parseAnd() (node, error) {
+	nodes := make([]node, 0)
+	for {
+		token := p.peek()
+		if token == "" || token == ")" || strings.EqualFold(token, "OR") || token == "|" {
+			break
+		}
+		if strings.EqualFold(token, "AND") || token == "&" {
+			p.pos++
+			continue
+		}
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, child)
+	}
+	if len(nodes) == 0 {
+		return nil, queryError(voice.Line("finder_query_missing_term"))
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].cost() < nodes[j].cost()
+	})
+	return andNode(nodes), nil
+}
+
+func (p *queryParser) parseUnary() (node, error) {
+	token := p.peek()
+	switch {
+	case strings.EqualFold(token, "NOT") || token == "!" || token == "-":
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner: inner}, nil
+	case len(token) > 1 && (token[0] == '-' || token[0] == '!'):
+		p.tokens[p.pos] = token[1:]
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner: inner}, nil
+	case token == "(":
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, queryError(voice.Line("finder_query_unclosed"))
+		}
+		p.pos++
+		return inner, nil
+	}
+	p.pos++
+	return p.parsePredicate(token)
+}
+
+var comparison = regexp.MustCompile(`^(size|mtime)(>=|<=|>|<|=|:)(.+)$`)
+
+func (p *queryParser) parsePredicate(token string) (node, error) {
+	if match := comparison.FindStringSubmatch(token); match != nil {
+		op := match[2]
+		if op == ":" {
+			op = "="
+		}
+		if match[1] == "size" {
+			return sizePredicate(op, match[3])
+		}
+		return timePredicate(op, match[3])
+	}
+	key, value, ok := strings.Cut(token, ":")
+	if !ok {
+		key, value = "name", token
+	}
+	if value == "" {
+		return nil, queryError(voice.Linef("finder_query_missing_value", key))
+	}
+	switch strings.ToLower(key) {
+	case "name":
+		return namePredicate(value), nil
+	case "path":
+		return pathPredicate(value)
+	case "ext":
+		ext := normalizeExt(value)
+		return predicate{weight: 1, test: func(c *candidate) bool {
+			return strings.EqualFold(filepath.Ext(c.info.Name()), ext)
+		}}, nil
+	case "type":
+		switch strings.ToLower(value) {
+		case "f", "file":
+			return predicate{weight: 1, test: func(c *candidate) bool { return !c.info.IsDir() }}, nil
+		case "d", "dir":
+			p.query.dirs = true
+			return predicate{weight: 1, test: func(c *candidate) bool { return c.info.IsDir() }}, nil
+		}
+	case "content":
+		return contentPredicate(value)
+	}
+	return nil, queryError(voice.Linef("finder_query_unknown", token))
+}
+
+func namePredicate(value string) node {
+	pattern := strings.ToLower(value)
+	glob := strings.ContainsAny(pattern, "*?[")
+	return predicate{weight: 1, test: func(c *candidate) bool {
+		name := strings.ToLower(c.info.Name())
+		if glob {
+			ok, _ := filepath.Match(pattern, name)
+			return ok
+		}
+		return strings.Contains(name, pattern)
+	}}
+}
+
+func pathPredicate(value string) (node, error) {
+	value = filepath.ToSlash(value)
+	if !strings.ContainsAny(value, "*?[") {
+		lower := strings.ToLower(value)
+		return predicate{weight: 1, test: func(c *candidate) bool {
+			return strings.Contains(strings.ToLower(c.rel), lower)
+		}}, nil
+	}
+	re, err := regexp.Compile(`(?i)(?:^|/)` + globToRegexp(strings.Trim(value, "/")) + `(?:/|$)`)
+	if err != nil {
+		return nil, queryError(err.Error())
+	}
+	return predicate{weight: 1, test: func(c *candidate) bool {
+		return re.MatchString(c.rel)
+	}}, nil
+}
+
+func sizePredicate(op, value string) (node, error) {
+	threshold, err := ParseSize(value)
+	if err != nil {
+		return nil, queryError(err.Error())
+	}
+	return predicate{weight: 1, test: func(c *candidate) bool {
+		if c.info.IsDir() {
+			return false
+		}
+		return compare(op, c.info.Size(), threshold)
+	}}, nil
+}
+
+func timePredicate(op, value string) (node, error) {
+	if day, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		next := day.AddDate(0, 0, 1)
+		return predicate{weight: 1, test: func(c *candidate) bool {
+			return matchDay(op, c.info.ModTime(), day, next)
+		}}, nil
+	}
+	age, unit, err := parseAge(value)
+	if err != nil {
+		return nil, err
+	}
+	return predicate{weight: 1, test: func(c *candidate) bool {
+		end := time.Now().Add(-age)
+		start := end
+		if op == "=" {
+			start = end.Add(-unit)
+		}
+		return matchSpan(op, c.info.ModTime(), start, end)
+	}}, nil
+}
+
+func matchSpan(op string, t, start, end time.Time) bool {
+	switch op {
+	case "<":
+		return !t.Before(end)
+	case "<=":
+		return !t.Before(start)
+	case ">":
+		return t.Before(start)
+	case ">=":
+		return t.Before(end)
+	default:
+		return !t.Before(start) && t.Before(end)
+	}
+}
+
+func matchDay(op string, t, start, end time.Time) bool {
+	switch op {
+	case "<":
+		return t.Before(start)
+	case "<=":
+		return t.Before(end)
+	case ">":
+		return !t.Before(end)
+	case ">=":
+		return !t.Before(start)
+	default:
+		return !t.Before(start) && t.Before(end)
+	}
+}
+
+func parseAge(value string) (time.Duration, time.Duration, error) {
+	units := map[byte]time.Duration{
+		's': time.Second,
+		'm': time.Minute,
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+		'y': 365 * 24 * time.Hour,
+	}
+	value = strings.ToLower(value)
+	unit, ok := units[value[len(value)-1]]
+	number := value[:len(value)-1]
+	if !ok {
+		unit, number = 24*time.Hour, value
+	}
+	amount, err := strconv.ParseFloat(number, 64)
+	if err != nil || amount < 0 {
+		return 0, 0, queryError(voice.Linef("finder_query_bad_time", value))
+	}
+	return time.Duration(amount * float64(unit)), unit, nil
+}
+
+func compare(op string, value, threshold int64) bool {
+	switch op {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	default:
+		return value == threshold
+	}
+}
+
+func contentPredicate(value string) (node, error) {
+	opts := ContentOptions{}
+	if len(value) > 1 && value[0] == '/' {
+		end := strings.LastIndexByte(value, '/')
+		if end > 0 {
+			flags := value[end+1:]
+			value = strings.ReplaceAll(value[1:end], `\/`, "/")
+			opts.Regex = true
+			opts.IgnoreCase = strings.Contains(flags, "i")
+		}
+	}
+	search, err := newContentSearch(value, opts)
+	if err != nil {
+		return nil, queryError(err.Error())
+	}
+	search.firstOnly = true
+	return predicate{weight: 10, test: func(c *candidate) bool {
+		if c.info.IsDir() {
+			return false
+		}
+		buf := chunkPool.Get().(*[]byte)
+		defer chunkPool.Put(buf)
+		results, binary := search.file(c.ctx, c.path, *buf)
+		return !binary && len(results) > 0
+	}}, nil
+}
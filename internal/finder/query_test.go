@@ -0,0 +1,209 @@
+package finder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testInfo struct {
+	name string
+	size int64
+	mod  time.Time
+	dir  bool
+}
+
+func (i testInfo) Name() string       { return i.name }
+func (i testInfo) Size() int64        { return i.size }
+func (i testInfo) Mode() os.FileMode  { return 0o644 }
+func (i testInfo) ModTime() time.Time { return i.mod }
+func (i testInfo) IsDir() bool        { return i.dir }
+func (i testInfo) Sys() any           { return nil }
+
+func TestLexQuery(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"name:*.log size>100M", []string{"name:*.log", "size>100M"}},
+		{"  ext:go\tAND\next:md ", []string{"ext:go", "AND", "ext:md"}},
+		{"(ext:go OR ext:md) -path:vendor", []string{"(", "ext:go", "OR", "ext:md", ")", "-path:vendor"}},
+		{`name:"my file.txt"`, []string{"name:my file.txt"}},
+		{`name:"say \"hi\""`, []string{`name:say "hi"`}},
+		{`content:/a b\/c)/i ext:go`, []string{`content:/a b\/c)/i`, "ext:go"}},
+		{"name:(x)", []string{"name:(x", ")"}},
+		{"", []string{}},
+	}
+	for _, tt := range tests {
+		got, err := lexQuery(tt.input)
+		if err != nil {
+			t.Errorf("lexQuery(%q): %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lexQuery(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestLexQueryUnclosed(t *testing.T) {
+	for _, input := range []string{`name:"x`, "content:/abc", `content:/a\/`} {
+		if _, err := lexQuery(input); err == nil {
+			t.Errorf("lexQuery(%q) succeeded, want error", input)
+		}
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	now := time.Now()
+	day := func(value string, hour int) time.Time {
+		d, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d.Add(time.Duration(hour) * time.Hour)
+	}
+	type target struct {
+		rel  string
+		info testInfo
+	}
+	file := func(rel string, size int64, mod time.Time) target {
+		return target{rel, testInfo{name: filepath.Base(rel), size: size, mod: mod}}
+	}
+	dir := func(rel string) target {
+		return target{rel, testInfo{name: filepath.Base(rel), mod: now, dir: true}}
+	}
+
+	tests := []struct {
+		query  string
+		target target
+		want   bool
+	}{
+		{"name:*.log", file("logs/app.log", 1, now), true},
+		{"name:*.log", file("logs/app.txt", 1, now), false},
+		{"name:APP", file("app.txt", 1, now), true},
+		{"log", file("catalog.txt", 1, now), true},
+		{"ext:go", file("main.go", 1, now), true},
+		{"ext:.GO", file("main.go", 1, now), true},
+		{"ext:go", file("main.gox", 1, now), false},
+		{"size>1K", file("a", 2048, now), true},
+		{"size>1K", file("a", 1024, now), false},
+		{"size>=1K", file("a", 1024, now), true},
+		{"size<=1K", file("a", 1025, now), false},
+		{"size:0", file("a", 0, now), true},
+		{"mtime<7d", file("a", 1, now.Add(-24*time.Hour)), true},
+		{"mtime<7d", file("a", 1, now.Add(-8*24*time.Hour)), false},
+		{"mtime>7d", file("a", 1, now.Add(-8*24*time.Hour)), true},
+		{"mtime>7d", file("a", 1, now.Add(-6*24*time.Hour)), false},
+		{"mtime=3d", file("a", 1, now.Add(-84*time.Hour)), true},
+		{"mtime=3d", file("a", 1, now.Add(-48*time.Hour)), false},
+		{"mtime<2h", file("a", 1, now.Add(-time.Hour)), true},
+		{"mtime=2024-01-05", file("a", 1, day("2024-01-05", 23)), true},
+		{"mtime=2024-01-05", file("a", 1, day("2024-01-06", 0)), false},
+		{"mtime<2024-01-05", file("a", 1, day("2024-01-04", 23)), true},
+		{"mtime<2024-01-05", file("a", 1, day("2024-01-05", 0)), false},
+		{"mtime<=2024-01-05", file("a", 1, day("2024-01-05", 12)), true},
+		{"mtime<=2024-01-05", file("a", 1, day("2024-01-06", 0)), false},
+		{"mtime>2024-01-05", file("a", 1, day("2024-01-06", 0)), true},
+		{"mtime>2024-01-05", file("a", 1, day("2024-01-05", 23)), false},
+		{"mtime>=2024-01-05", file("a", 1, day("2024-01-05", 0)), true},
+		{"mtime>=2024-01-05", file("a", 1, day("2024-01-04", 23)), false},
+		{"path:vendor", file("vendor/x.go", 1, now), true},
+		{"path:vendor", file("x.go", 1, now), false},
+		{"path:src/**/*.go", file("src/a/b/c.go", 1, now), true},
+		{"path:src/*.go", file("src/a/c.go", 1, now), false},
+		{"path:deploy/*", file("app/deploy/prod.yaml", 1, now), true},
+		{"ext:go -path:vendor", file("vendor/x.go", 1, now), false},
+		{"ext:go !path:vendor", file("cmd/x.go", 1, now), true},
+		{"ext:go OR ext:md", file("README.md", 1, now), true},
+		{"ext:go | ext:md", file("a.txt", 1, now), false},
+		{"ext:go AND size>1K", file("a.go", 1, now), false},
+		{"NOT (ext:go OR ext:md)", file("a.txt", 1, now), true},
+		{"NOT (ext:go OR ext:md)", file("a.md", 1, now), false},
+		{"(ext:go OR ext:md) size<1K", file("a.md", 1, now), true},
+		{"type:d name:src", dir("src"), true},
+		{"type:f", dir("src"), false},
+		{"name:src", dir("src"), false},
+		{"size>0", dir("src"), false},
+	}
+	for _, tt := range tests {
+		query, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): %v", tt.query, err)
+			continue
+		}
+		root := filepath.FromSlash("/src/vendor")
+		path := filepath.Join(root, filepath.FromSlash(tt.target.rel))
+		if got := query.Match(context.Background(), root, path, tt.target.info); got != tt.want {
+			t.Errorf("%q on %s = %v, want %v", tt.query, tt.target.rel, got, tt.want)
+		}
+	}
+}
+
+func TestContentPredicate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(path, []byte("one\nneedle\nNeedle\nneedle\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		query string
+		ctx   context.Context
+		want  bool
+	}{
+		{"content:needle", context.Background(), true},
+		{"content:missing", context.Background(), false},
+		{"content:/^NEEDLE$/i", context.Background(), true},
+		{"content:/^NEEDLE$/", context.Background(), false},
+		{"content:needle", cancelled, false},
+	}
+	for _, tt := range tests {
+		query, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := query.Match(tt.ctx, dir, path, info); got != tt.want {
+			t.Errorf("%q (ctx err %v) = %v, want %v", tt.query, tt.ctx.Err(), got, tt.want)
+		}
+	}
+
+	search, err := newContentSearch("needle", ContentOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	search.firstOnly = true
+	results, _ := search.file(context.Background(), path, make([]byte, 4))
+	if len(results) != 1 || results[0].Line != 2 {
+		t.Errorf("first-match search = %+v, want only line 2", results)
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"OR",
+		"ext:go OR",
+		"(ext:go",
+		"ext:go )",
+		"ext:",
+		"size>abc",
+		"mtime<abc",
+		"mtime<-3d",
+		"color:red",
+		"type:x",
+		"content:/[/",
+	} {
+		if _, err := ParseQuery(input); err == nil {
+			t.Errorf("ParseQuery(%q) succeeded, want error", input)
+		}
+	}
+}
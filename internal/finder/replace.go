@@ -0,0 +1,349 @@
+package finder
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sakibox/internal/storage"
+	"sakibox/internal/voice"
+)
+
+type FileChange struct {
+	Path  string
+	Hunks []ReplaceHunk
+	mode  os.FileMode
+	lines []string
+	sum   string
+}
+
+type ReplaceHunk struct {
+	Start    int
+	NewStart int
+	Old      []string
+	New      []string
+	Changed  int
+	Accepted bool
+}
+
+type ReplaceEntry struct {
+	Path   string      `json:"path"`
+	Backup string      `json:"backup"`
+	Mode   os.FileMode `json:"mode"`
+	Sum    string      `json:"sum"`
+}
+
+type ReplaceBatch struct {
+	ID          string         `json:"id"`
+	Time        time.Time      `json:"time"`
+	Query       string         `json:"query"`
+	Replacement string         `json:"replacement"`
+	Files       []ReplaceEntry `json:"files"`
+}
+
+const (
+	replaceContext    = 3
+	maxReplaceBatches = 20
+)
+
+var replaceStore = storage.Store{Name: "replace.json", Version: 1}
+
+func PlanReplace(ctx context.Context, root, query, replacement string, opts ContentOptions) ([]FileChange, error) {
+	if opts.Invert {
+		return nil, errors.New(voice.Line("finder_replace_invert"))
+	}
+	opts.Before, opts.After, opts.Archives, opts.Progress = 0, 0, false, nil
+	search, err := newContentSearch(query, opts)
+	if err != nil {
+		return nil, err
+	}
+	var stopped error
+	opts.Progress = func(p Progress) {
+		if p.Done {
+			stopped = p.Err
+		}
+	}
+	stream, err := SearchContent(ctx, root, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0)
+	seen := make(map[string]bool)
+	for batch := range stream {
+		for _, result := range batch {
+			if _, _, inArchive := SplitArchivePath(result.Path); inArchive {
+				continue
+			}
+			real, err := filepath.EvalSymlinks(result.Path)
+			if err != nil || seen[real] {
+				continue
+			}
+			seen[real] = true
+			paths = append(paths, result.Path)
+		}
+	}
+	if stopped != nil {
+		return nil, stopped
+	}
+	sort.Strings(paths)
+
+	changes := make([]FileChange, 0, len(paths))
+	for _, path := range paths {
+		change, ok, err := planFile(path, search, []byte(replacement), opts.Regex)
+		if err != nil {
+			return changes, err
+		}
+		if ok {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+func planFile(path string, search *contentSearch, replacement []byte, expand bool) (FileChange, bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileChange{}, false, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FileChange{}, false, err
+	}
+	change := FileChange{Path: path, mode: info.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky), lines: strings.Split(string(data), "\n"), sum: checksum(data)}
+	replaced := make([]string, len(change.lines))
+	changed := make([]int, 0)
+	for i, line := range change.lines {
+		replaced[i] = string(search.replace([]byte(line), replacement, expand))
+		if replaced[i] != line {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return FileChange{}, false, nil
+	}
+
+	limit := len(change.lines)
+	if change.lines[limit-1] == "" {
+		limit--
+	}
+	delta := 0
+	for i := 0; i < len(changed); {
+		start := max(changed[i]-replaceContext, 0)
+		last := changed[i]
+		count := 0
+		for i < len(changed) && changed[i] <= last+2*replaceContext {
+			last = changed[i]
+			count++
+			i++
+		}
+		end := min(last+replaceContext+1, limit)
+		hunk := ReplaceHunk{
+			Start:    start,
+			NewStart: start + delta,
+			Old:      change.lines[start:end],
+			New:      replaced[start:end],
+			Changed:  count,
+			Accepted: true,
+		}
+		delta += hunk.newLines() - len(hunk.Old)
+		change.Hunks = append(change.Hunks, hunk)
+	}
+	return change, true, nil
+}
+
+func (s *contentSearch) replace(line, replacement []byte, expand bool) []byte {
+	switch {
+	case s.re == nil:
+		return bytes.ReplaceAll(line, s.literal, replacement)
+	case expand:
+		return s.re.ReplaceAll(line, replacement)
+	default:
+		return s.re.ReplaceAllLiteral(line, replacement)
+	}
+}
+
+func (h ReplaceHunk) newLines() int {
+	count := 0
+	for _, line := range h.New {
+		count += strings.Count(line, "\n") + 1
+	}
+	return count
+}
+
+func (h ReplaceHunk) Diff() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@", h.Start+1, len(h.Old), h.NewStart+1, h.newLines())
+	for i, old := range h.Old {
+		if old == h.New[i] {
+			out.WriteString("\n " + old)
+			continue
+		}
+		out.WriteString("\n-" + old)
+		for _, line := range strings.Split(h.New[i], "\n") {
+			out.WriteString("\n+" + line)
+		}
+	}
+	return out.String()
+}
+
+func (c FileChange) Accepted() int {
+	count := 0
+	for _, hunk := range c.Hunks {
+		if hunk.Accepted {
+			count++
+		}
+	}
+	return count
+}
+
+func (c FileChange) result() []byte {
+	lines := make([]string, 0, len(c.lines))
+	next := 0
+	for _, hunk := range c.Hunks {
+		if !hunk.Accepted {
+			continue
+		}
+		lines = append(lines, c.lines[next:hunk.Start]...)
+		lines = append(lines, hunk.New...)
+		next = hunk.Start + len(hunk.Old)
+	}
+	lines = append(lines, c.lines[next:]...)
+	return []byte(strings.Join(lines, "\n"))
+}
+
+func ApplyReplace(query, replacement string, changes []FileChange) (ReplaceBatch, error) {
+	dir, err := storage.Path("replace")
+	if err != nil {
+		return ReplaceBatch{}, err
+	}
+	now := time.Now()
+	batch := ReplaceBatch{ID: strconv.FormatInt(now.UnixNano(), 36), Time: now, Query: query, Replacement: replacement, Files: make([]ReplaceEntry, 0)}
+	target := filepath.Join(dir, batch.ID)
+	if err := os.MkdirAll(target, 0700); err != nil {
+		return ReplaceBatch{}, err
+	}
+	var failed error
+	for i, change := range changes {
+		if change.Accepted() == 0 {
+			continue
+		}
+		original, err := os.ReadFile(change.Path)
+		if err != nil {
+			failed = errors.Join(failed, err)
+			continue
+		}
+		if checksum(original) != change.sum {
+			failed = errors.Join(failed, errors.New(voice.Linef("finder_replace_stale", change.Path)))
+			continue
+		}
+		real, err := filepath.EvalSymlinks(change.Path)
+		if err != nil {
+			failed = errors.Join(failed, err)
+			continue
+		}
+		abs, err := filepath.Abs(real)
+		if err != nil {
+			failed = errors.Join(failed, err)
+			continue
+		}
+		backup := filepath.Join(target, fmt.Sprintf("%d-%s", i+1, filepath.Base(abs)))
+		if err := os.WriteFile(backup, original, 0600); err != nil {
+			failed = errors.Join(failed, err)
+			continue
+		}
+		data := change.result()
+		if err := storage.WriteFileAtomic(abs, data, change.mode); err != nil {
+			_ = os.Remove(backup)
+			failed = errors.Join(failed, err)
+			continue
+		}
+		batch.Files = append(batch.Files, ReplaceEntry{Path: abs, Backup: backup, Mode: change.mode, Sum: checksum(data)})
+	}
+	if len(batch.Files) == 0 {
+		_ = os.Remove(target)
+		return batch, failed
+	}
+	batches := make([]ReplaceBatch, 0)
+	err = replaceStore.Update(&batches, func() error {
+		batches = append(batches, batch)
+		if len(batches) > maxReplaceBatches {
+			for _, old := range batches[:len(batches)-maxReplaceBatches] {
+				_ = os.RemoveAll(filepath.Join(dir, old.ID))
+			}
+			batches = batches[len(batches)-maxReplaceBatches:]
+		}
+		return nil
+	})
+	return batch, errors.Join(failed, err)
+}
+
+func UndoReplace() (ReplaceBatch, error) {
+	var restored ReplaceBatch
+	var failed error
+	batches := make([]ReplaceBatch, 0)
+	err := replaceStore.Update(&batches, func() error {
+		if len(batches) == 0 {
+			return errors.New(voice.Line("finder_replace_nothing"))
+		}
+		last := &batches[len(batches)-1]
+		restored = *last
+		restored.Files = make([]ReplaceEntry, 0, len(last.Files))
+		for _, entry := range last.Files {
+			current, err := os.ReadFile(entry.Path)
+			if err != nil {
+				return err
+			}
+			if checksum(current) != entry.Sum {
+				return errors.New(voice.Linef("finder_replace_modified", entry.Path))
+			}
+		}
+		for len(last.Files) > 0 {
+			entry := last.Files[0]
+			original, err := os.ReadFile(entry.Backup)
+			if err == nil {
+				err = storage.WriteFileAtomic(entry.Path, original, entry.Mode)
+			}
+			if err != nil && len(restored.Files) == 0 {
+				return err
+			}
+			if err != nil {
+				failed = err
+				return nil
+			}
+			_ = os.Remove(entry.Backup)
+			restored.Files = append(restored.Files, entry)
+			last.Files = last.Files[1:]
+		}
+		if dir, err := storage.Path("replace"); err == nil {
+			_ = os.RemoveAll(filepath.Join(dir, restored.ID))
+		}
+		batches = batches[:len(batches)-1]
+		return nil
+	})
+	return restored, errors.Join(failed, err)
+}
+
+func LastReplace() (ReplaceBatch, bool, error) {
+	batches := make([]ReplaceBatch, 0)
+	if err := replaceStore.Load(&batches); err != nil {
+		return ReplaceBatch{}, false, err
+	}
+	if len(batches) == 0 {
+		return ReplaceBatch{}, false, nil
+	}
+	return batches[len(batches)-1], true, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,150 @@
+package finder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func numbered(count int, marked ...int) string {
+	lines := make([]string, count)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line%d", i)
+	}
+	for _, i := range marked {
+		lines[i] = "foo"
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func TestPlanFileHunks(t *testing.T) {
+	type shape struct {
+		start, newStart, old, new, changed int
+	}
+	tests := []struct {
+		name        string
+		content     string
+		query       string
+		replacement string
+		regex       bool
+		hunks       []shape
+		reject      int
+		result      string
+	}{
+		{
+			name:    "single change",
+			content: "a\nfoo\nb\n",
+			query:   "foo", replacement: "bar",
+			hunks:  []shape{{0, 0, 3, 3, 1}},
+			reject: -1,
+			result: "a\nbar\nb\n",
+		},
+		{
+			name:    "no trailing newline",
+			content: "a\nfoo",
+			query:   "foo", replacement: "bar",
+			hunks:  []shape{{0, 0, 2, 2, 1}},
+			reject: -1,
+			result: "a\nbar",
+		},
+		{
+			name:    "far apart changes split",
+			content: numbered(20, 1, 15),
+			query:   "foo", replacement: "bar",
+			hunks:  []shape{{0, 0, 5, 5, 1}, {12, 12, 7, 7, 1}},
+			reject: -1,
+			result: strings.ReplaceAll(numbered(20, 1, 15), "foo", "bar"),
+		},
+		{
+			name:    "close changes merge",
+			content: numbered(20, 4, 10),
+			query:   "foo", replacement: "bar",
+			hunks:  []shape{{1, 1, 13, 13, 2}},
+			reject: -1,
+			result: strings.ReplaceAll(numbered(20, 4, 10), "foo", "bar"),
+		},
+		{
+			name:    "inserted lines shift later hunks",
+			content: numbered(20, 1, 15),
+			query:   "foo", replacement: "x\ny",
+			hunks:  []shape{{0, 0, 5, 6, 1}, {12, 13, 7, 8, 1}},
+			reject: -1,
+			result: strings.ReplaceAll(numbered(20, 1, 15), "foo", "x\ny"),
+		},
+		{
+			name:    "rejected hunk keeps original lines",
+			content: numbered(20, 1, 15),
+			query:   "foo", replacement: "x\ny",
+			hunks:  []shape{{0, 0, 5, 6, 1}, {12, 13, 7, 8, 1}},
+			reject: 0,
+			result: strings.Replace(numbered(20, 1, 15), "line14\nfoo", "line14\nx\ny", 1),
+		},
+		{
+			name:    "regex expands groups",
+			content: "key=1\nother\n",
+			query:   `(\w+)=(\d)`, replacement: "$2=$1", regex: true,
+			hunks:  []shape{{0, 0, 2, 2, 1}},
+			reject: -1,
+			result: "1=key\nother\n",
+		},
+		{
+			name:    "no match",
+			content: "a\nb\n",
+			query:   "foo", replacement: "bar",
+			reject: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file.txt")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			search, err := newContentSearch(tt.query, ContentOptions{Regex: tt.regex})
+			if err != nil {
+				t.Fatal(err)
+			}
+			change, ok, err := planFile(path, search, []byte(tt.replacement), tt.regex)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != (len(tt.hunks) > 0) {
+				t.Fatalf("planFile changed = %v, want %v", ok, len(tt.hunks) > 0)
+			}
+			if len(change.Hunks) != len(tt.hunks) {
+				t.Fatalf("got %d hunks, want %d", len(change.Hunks), len(tt.hunks))
+			}
+			for i, want := range tt.hunks {
+				h := change.Hunks[i]
+				got := shape{h.Start, h.NewStart, len(h.Old), h.newLines(), h.Changed}
+				if got != want {
+					t.Errorf("hunk %d = %+v, want %+v", i, got, want)
+				}
+			}
+			if !ok {
+				return
+			}
+			if tt.reject >= 0 {
+				change.Hunks[tt.reject].Accepted = false
+			}
+			if got := string(change.result()); got != tt.result {
+				t.Errorf("result = %q, want %q", got, tt.result)
+			}
+		})
+	}
+}
+
+func TestReplaceHunkDiff(t *testing.T) {
+	hunk := ReplaceHunk{
+		Start:    4,
+		NewStart: 6,
+		Old:      []string{"a", "foo", "b"},
+		New:      []string{"a", "x\ny", "b"},
+	}
+	want := "@@ -5,3 +7,4 @@\n a\n-foo\n+x\n+y\n b"
+	if got := hunk.Diff(); got != want {
+		t.Errorf("Diff() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package finder
+
+import "os"
+
+func inode(info os.FileInfo) uint64 {
+	return 0
+}
+
+func diskStat(info os.FileInfo) (usage int64, device uint64, link [2]uint64, linked bool) {
+	return info.Size(), 0, link, false
+}
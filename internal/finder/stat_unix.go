@@ -0,0 +1,24 @@
+//go:build unix
+
+package finder
+
+import (
+	"os"
+	"syscall"
+)
+
+func inode(info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino)
+	}
+	return 0
+}
+
+func diskStat(info os.FileInfo) (usage int64, device uint64, link [2]uint64, linked bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return info.Size(), 0, link, false
+	}
+	link = [2]uint64{uint64(stat.Dev), uint64(stat.Ino)}
+	return int64(stat.Blocks) * 512, uint64(stat.Dev), link, !info.IsDir() && stat.Nlink > 1
+}
@@ -0,0 +1,322 @@
+package finder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"sakibox/internal/voice"
+)
+
+type StructResult struct {
+	Path    string
+	Doc     int
+	Docs    int
+	Line    int
+	KeyPath string
+	Value   string
+}
+
+type segmentKind int
+
+const (
+	segmentKey segmentKind = iota
+	segmentAnyKey
+	segmentIndex
+	segmentAnyIndex
+	segmentDeep
+)
+
+type pathSegment struct {
+	kind  segmentKind
+	key   string
+	index int
+}
+
+var structuredExts = map[string]bool{".json": true, ".yaml": true, ".yml": true, ".toml": true}
+
+func FindStructured(ctx context.Context, root, keyPath, value string) ([]StructResult, error) {
+	segments, err := parseKeyPath(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	matchValue := valueMatcher(value)
+	opts, err := loadWalkOptions()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := opts.budget(ctx)
+	defer cancel()
+	limited := false
+
+	paths := make(chan string)
+	var mu sync.Mutex
+	results := make([]StructResult, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				if ctx.Err() != nil {
+					continue
+				}
+				found := searchStructuredFile(path, segments, matchValue)
+				if len(found) == 0 {
+					continue
+				}
+				mu.Lock()
+				results = append(results, found...)
+				if opts.maxResults > 0 && len(results) >= opts.maxResults {
+					limited = true
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	err = forEachFile(ctx, root, opts, func(path string, entry os.DirEntry) error {
+		if !structuredExts[strings.ToLower(filepath.Ext(entry.Name()))] {
+			return nil
+		}
+		select {
+		case paths <- path:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	close(paths)
+	wg.Wait()
+	if limited {
+		err = ErrMaxResults
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Path != results[j].Path {
+			return results[i].Path < results[j].Path
+		}
+		if results[i].Doc != results[j].Doc {
+			return results[i].Doc < results[j].Doc
+		}
+		return results[i].Line < results[j].Line
+	})
+	if limited {
+		results = results[:opts.maxResults]
+	}
+	return results, err
+}
+
+func searchStructuredFile(path string, segments []pathSegment, matchValue func(string) bool) []StructResult {
+	data, err := os.ReadFile(path)
+	if err != nil || bytes.IndexByte(data[:min(len(data), binarySniffSize)], 0) >= 0 {
+		return nil
+	}
+	docs, err := parseStructured(path, data)
+	if err != nil {
+		return nil
+	}
+	results := make([]StructResult, 0)
+	for i, doc := range docs {
+		walkKeyPath(doc, segments, "", func(keyPath string, node *yaml.Node) {
+			value := nodeValue(node)
+			if !matchValue(value) {
+				return
+			}
+			results = append(results, StructResult{
+				Path:    path,
+				Doc:     i + 1,
+				Docs:    len(docs),
+				Line:    node.Line,
+				KeyPath: keyPath,
+				Value:   value,
+			})
+		})
+	}
+	return results
+}
+
+func parseStructured(path string, data []byte) ([]*yaml.Node, error) {
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		doc, err := parseTOML(data)
+		if err != nil {
+			return nil, err
+		}
+		return []*yaml.Node{doc}, nil
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	docs := make([]*yaml.Node, 0, 1)
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return docs, err
+		}
+		if len(doc.Content) > 0 {
+			docs = append(docs, doc.Content[0])
+		}
+	}
+}
+
+func parseKeyPath(expression string) ([]pathSegment, error) {
+	expression = strings.TrimSpace(expression)
+	segments := make([]pathSegment, 0)
+	invalid := func() error {
+		return errors.New(voice.Linef("finder_struct_bad_path", expression))
+	}
+	for i := 0; i < len(expression); {
+		switch c := expression[i]; {
+		case c == '.':
+			i++
+		case c == '[':
+			end := strings.IndexByte(expression[i:], ']')
+			if end < 0 {
+				return nil, invalid()
+			}
+			inner := strings.TrimSpace(expression[i+1 : i+end])
+			i += end + 1
+			switch {
+			case inner == "*":
+				segments = append(segments, pathSegment{kind: segmentAnyIndex})
+			case len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\'') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, pathSegment{kind: segmentKey, key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, invalid()
+				}
+				segments = append(segments, pathSegment{kind: segmentIndex, index: index})
+			}
+		default:
+			end := i
+			for end < len(expression) && expression[end] != '.' && expression[end] != '[' {
+				end++
+			}
+			switch key := expression[i:end]; key {
+			case "**":
+				segments = append(segments, pathSegment{kind: segmentDeep})
+			case "*":
+				segments = append(segments, pathSegment{kind: segmentAnyKey})
+			default:
+				segments = append(segments, pathSegment{kind: segmentKey, key: key})
+			}
+			i = end
+		}
+	}
+	return segments, nil
+}
+
+func walkKeyPath(node *yaml.Node, segments []pathSegment, keyPath string, emit func(string, *yaml.Node)) {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	if len(segments) == 0 {
+		if keyPath == "" {
+			walkKeyPath(node, []pathSegment{{kind: segmentDeep}}, keyPath, emit)
+			return
+		}
+		emit(keyPath, node)
+		return
+	}
+	segment, rest := segments[0], segments[1:]
+	switch segment.kind {
+	case segmentDeep:
+		if len(rest) == 0 {
+			if node.Kind == yaml.ScalarNode {
+				emit(keyPath, node)
+			}
+		} else {
+			walkKeyPath(node, rest, keyPath, emit)
+		}
+		eachChild(node, keyPath, func(childPath string, child *yaml.Node) {
+			walkKeyPath(child, segments, childPath, emit)
+		})
+	case segmentKey, segmentAnyKey:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if segment.kind == segmentKey && key != segment.key {
+				continue
+			}
+			walkKeyPath(node.Content[i+1], rest, joinKeyPath(keyPath, key), emit)
+		}
+	case segmentIndex, segmentAnyIndex:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, child := range node.Content {
+			if segment.kind == segmentIndex && i != segment.index {
+				continue
+			}
+			walkKeyPath(child, rest, fmt.Sprintf("%s[%d]", keyPath, i), emit)
+		}
+	}
+}
+
+func eachChild(node *yaml.Node, keyPath string, fn func(string, *yaml.Node)) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			fn(joinKeyPath(keyPath, node.Content[i].Value), node.Content[i+1])
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			fn(fmt.Sprintf("%s[%d]", keyPath, i), child)
+		}
+	}
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func joinKeyPath(keyPath, key string) string {
+	if !bareKey.MatchString(key) {
+		return keyPath + "[" + strconv.Quote(key) + "]"
+	}
+	if keyPath == "" {
+		return key
+	}
+	return keyPath + "." + key
+}
+
+func nodeValue(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		return node.Value
+	}
+	flow := *node
+	flow.Style = yaml.FlowStyle
+	data, err := yaml.Marshal(&flow)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func valueMatcher(value string) func(string) bool {
+	if value == "" {
+		return func(string) bool { return true }
+	}
+	if strings.ContainsAny(value, "*?") {
+		re, err := regexp.Compile("^" + strings.ReplaceAll(strings.ReplaceAll(regexp.QuoteMeta(value), `\*`, ".*"), `\?`, ".") + "$")
+		if err == nil {
+			return re.MatchString
+		}
+	}
+	return func(candidate string) bool { return strings.Contains(candidate, value) }
+}
@@ -0,0 +1,405 @@
+package finder
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"sakibox/internal/voice"
+)
+
+type tomlKind int
+
+const (
+	tomlImplicit tomlKind = iota
+	tomlHeader
+	tomlDotted
+	tomlSealed
+	tomlArray
+)
+
+var (
+	tomlInt      = regexp.MustCompile(`^([+-]?(0|[1-9](_?[0-9])*)|0x[0-9A-Fa-f](_?[0-9A-Fa-f])*|0o[0-7](_?[0-7])*|0b[01](_?[01])*)$`)
+	tomlFloat    = regexp.MustCompile(`^([+-]?(0|[1-9](_?[0-9])*)(\.[0-9](_?[0-9])*([eE][+-]?[0-9](_?[0-9])*)?|[eE][+-]?[0-9](_?[0-9])*)|[+-]?(inf|nan))$`)
+	tomlDateTime = regexp.MustCompile(`^([0-9]{4}-[0-9]{2}-[0-9]{2}([Tt ][0-9]{2}:[0-9]{2}(:[0-9]{2}(\.[0-9]+)?)?([Zz]|[+-][0-9]{2}:[0-9]{2})?)?|[0-9]{2}:[0-9]{2}(:[0-9]{2}(\.[0-9]+)?)?)$`)
+)
+
+func parseTOML(data []byte) (*yaml.Node, error) {
+	p := &tomlParser{src: []rune(string(data)), line: 1, kinds: make(map[*yaml.Node]tomlKind)}
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1, Column: 1}
+	current := root
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return root, nil
+		}
+		line := p.line
+		if p.peek() == '[' {
+			array := p.consume("[[")
+			if !array {
+				p.pos++
+			}
+			keys, err := p.keys()
+			if err != nil {
+				return nil, err
+			}
+			p.skipSpace(false)
+			closing := "]"
+			if array {
+				closing = "]]"
+			}
+			if !p.consume(closing) {
+				return nil, p.error()
+			}
+			parent := root
+			for _, key := range keys[:len(keys)-1] {
+				if parent, err = p.table(parent, key, line, false); err != nil {
+					return nil, err
+				}
+			}
+			last := keys[len(keys)-1]
+			child := tomlChild(parent, last)
+			switch {
+			case array && child == nil:
+				child = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: line}
+				p.kinds[child] = tomlArray
+				tomlSet(parent, last, child, line)
+				fallthrough
+			case array && p.kinds[child] == tomlArray:
+				current = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: line}
+				child.Content = append(child.Content, current)
+			case !array && child == nil:
+				current = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: line}
+				tomlSet(parent, last, current, line)
+			case !array && child.Kind == yaml.MappingNode && p.kinds[child] == tomlImplicit:
+				current = child
+			default:
+				return nil, p.error()
+			}
+			if !array {
+				p.kinds[current] = tomlHeader
+			}
+			if err := p.endLine(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := p.keyValue(current); err != nil {
+			return nil, err
+		}
+		if err := p.endLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+type tomlParser struct {
+	src   []rune
+	pos   int
+	line  int
+	kinds map[*yaml.Node]tomlKind
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *tomlParser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *tomlParser) consume(token string) bool {
+	runes := []rune(token)
+	if p.pos+len(runes) > len(p.src) || string(p.src[p.pos:p.pos+len(runes)]) != token {
+		return false
+	}
+	p.pos += len(runes)
+	return true
+}
+
+func (p *tomlParser) error() error {
+	return errors.New(voice.Linef("finder_struct_bad_toml", p.line))
+}
+
+func (p *tomlParser) skipSpace(newlines bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n' && newlines:
+			p.pos++
+			p.line++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) endLine() error {
+	p.skipSpace(false)
+	if !p.eof() && p.peek() != '\n' {
+		return p.error()
+	}
+	return nil
+}
+
+func (p *tomlParser) keys() ([]string, error) {
+	keys := make([]string, 0, 1)
+	for {
+		p.skipSpace(false)
+		var key string
+		switch p.peek() {
+		case '"', '\'':
+			if p.consume(`"""`) || p.consume("'''") {
+				return nil, p.error()
+			}
+			value, err := p.str()
+			if err != nil {
+				return nil, err
+			}
+			key = value
+		default:
+			start := p.pos
+			for !p.eof() && strings.ContainsRune("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789_-", p.peek()) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, p.error()
+			}
+			key = string(p.src[start:p.pos])
+		}
+		keys = append(keys, key)
+		p.skipSpace(false)
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *tomlParser) keyValue(table *yaml.Node) error {
+	line := p.line
+	keys, err := p.keys()
+	if err != nil {
+		return err
+	}
+	p.skipSpace(false)
+	if !p.consume("=") {
+		return p.error()
+	}
+	p.skipSpace(false)
+	value, err := p.value()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys[:len(keys)-1] {
+		if table, err = p.table(table, key, line, true); err != nil {
+			return err
+		}
+	}
+	if tomlChild(table, keys[len(keys)-1]) != nil {
+		return errors.New(voice.Linef("finder_struct_bad_toml", line))
+	}
+	tomlSet(table, keys[len(keys)-1], value, line)
+	return nil
+}
+
+func (p *tomlParser) value() (*yaml.Node, error) {
+	line := p.line
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		value, err := p.str()
+		if err != nil {
+			return nil, err
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Line: line}, nil
+	case c == '[':
+		p.pos++
+		list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: line}
+		p.kinds[list] = tomlSealed
+		for {
+			p.skipSpace(true)
+			if p.consume("]") {
+				return list, nil
+			}
+			item, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			list.Content = append(list.Content, item)
+			p.skipSpace(true)
+			if !p.consume(",") && p.peek() != ']' {
+				return nil, p.error()
+			}
+		}
+	case c == '{':
+		p.pos++
+		table := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: line}
+		for {
+			p.skipSpace(false)
+			if p.consume("}") {
+				p.kinds[table] = tomlSealed
+				return table, nil
+			}
+			if err := p.keyValue(table); err != nil {
+				return nil, err
+			}
+			p.skipSpace(false)
+			if !p.consume(",") && p.peek() != '}' {
+				return nil, p.error()
+			}
+		}
+	default:
+		start := p.pos
+		for !p.eof() && !strings.ContainsRune(",]}#\n", p.peek()) {
+			p.pos++
+		}
+		raw := strings.TrimSpace(string(p.src[start:p.pos]))
+		tag := tomlTag(raw)
+		if tag == "" {
+			return nil, p.error()
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: raw, Line: line}, nil
+	}
+}
+
+func (p *tomlParser) str() (string, error) {
+	quote := p.peek()
+	multi := p.consume(strings.Repeat(string(quote), 3))
+	if !multi {
+		p.pos++
+	} else if p.consume("\n") || p.consume("\r\n") {
+		p.line++
+	}
+	var out strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		switch {
+		case multi && p.consume(strings.Repeat(string(quote), 3)):
+			for i := 0; i < 2 && p.peek() == quote; i++ {
+				out.WriteRune(quote)
+				p.pos++
+			}
+			return out.String(), nil
+		case !multi && c == quote:
+			p.pos++
+			return out.String(), nil
+		case c == '\n' && !multi:
+			return "", p.error()
+		case c == '\\' && quote == '"':
+			p.pos++
+			if err := p.escape(&out, multi); err != nil {
+				return "", err
+			}
+			continue
+		case c == '\n':
+			p.line++
+		}
+		out.WriteRune(c)
+		p.pos++
+	}
+	return "", p.error()
+}
+
+func (p *tomlParser) escape(out *strings.Builder, multi bool) error {
+	escaped := p.peek()
+	p.pos++
+	simple := map[rune]rune{'b': '\b', 't': '\t', 'n': '\n', 'f': '\f', 'r': '\r', 'e': '\x1b', '"': '"', '\\': '\\'}
+	if r, ok := simple[escaped]; ok {
+		out.WriteRune(r)
+		return nil
+	}
+	digits := map[rune]int{'x': 2, 'u': 4, 'U': 8}[escaped]
+	if digits > 0 {
+		if p.pos+digits > len(p.src) {
+			return p.error()
+		}
+		code, err := strconv.ParseUint(string(p.src[p.pos:p.pos+digits]), 16, 32)
+		if err != nil || code > 0x10FFFF || code >= 0xD800 && code <= 0xDFFF {
+			return p.error()
+		}
+		p.pos += digits
+		out.WriteRune(rune(code))
+		return nil
+	}
+	if multi {
+		for p.pos--; p.peek() == ' ' || p.peek() == '\t' || p.peek() == '\r'; {
+			p.pos++
+		}
+		if p.peek() == '\n' {
+			p.skipBlank()
+			return nil
+		}
+	}
+	return p.error()
+}
+
+func (p *tomlParser) skipBlank() {
+	for !p.eof() {
+		switch p.peek() {
+		case '\n':
+			p.line++
+		case ' ', '\t', '\r':
+		default:
+			return
+		}
+		p.pos++
+	}
+}
+
+func (p *tomlParser) table(parent *yaml.Node, key string, line int, dotted bool) (*yaml.Node, error) {
+	child := tomlChild(parent, key)
+	if child == nil {
+		child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: line}
+		if dotted {
+			p.kinds[child] = tomlDotted
+		}
+		tomlSet(parent, key, child, line)
+		return child, nil
+	}
+	kind := p.kinds[child]
+	switch {
+	case child.Kind == yaml.SequenceNode && kind == tomlArray && !dotted:
+		return child.Content[len(child.Content)-1], nil
+	case child.Kind != yaml.MappingNode || kind == tomlSealed || dotted && kind == tomlHeader:
+		return nil, errors.New(voice.Linef("finder_struct_bad_toml", line))
+	}
+	return child, nil
+}
+
+func tomlTag(raw string) string {
+	switch {
+	case raw == "true" || raw == "false":
+		return "!!bool"
+	case tomlInt.MatchString(raw):
+		return "!!int"
+	case tomlFloat.MatchString(raw):
+		return "!!float"
+	case tomlDateTime.MatchString(raw):
+		return "!!timestamp"
+	}
+	return ""
+}
+
+func tomlChild(table *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(table.Content); i += 2 {
+		if table.Content[i].Value == key {
+			return table.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func tomlSet(table *yaml.Node, key string, value *yaml.Node, line int) {
+	table.Content = append(table.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key, Line: line}, value)
+}
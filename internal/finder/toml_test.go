@@ -0,0 +1,115 @@
+package finder
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		path []string
+		want string
+		line int
+	}{
+		{"bare key", "a = 1", []string{"a"}, "1", 1},
+		{"string", `a = "x"`, []string{"a"}, "x", 1},
+		{"literal string", `a = 'C:\dir'`, []string{"a"}, `C:\dir`, 1},
+		{"short unicode escape", `a = "x\u00e9y"`, []string{"a"}, "xéy", 1},
+		{"long unicode escape", `a = "\U0001F600"`, []string{"a"}, "😀", 1},
+		{"simple escapes", `a = "\t\"\\\n"`, []string{"a"}, "\t\"\\\n", 1},
+		{"multi-line string", "a = \"\"\"\nx\ny\"\"\"", []string{"a"}, "x\ny", 1},
+		{"line-ending backslash", "a = \"\"\"\nx \\\n   y\"\"\"", []string{"a"}, "x y", 1},
+		{"multi-line literal", "a = '''\nx\\n'''", []string{"a"}, `x\n`, 1},
+		{"comment", "a = 1 # note", []string{"a"}, "1", 1},
+		{"table", "[t]\nb = true", []string{"t", "b"}, "true", 2},
+		{"dotted key", "t.b = 2", []string{"t", "b"}, "2", 1},
+		{"dotted header", "[a.b]\nc = 3", []string{"a", "b", "c"}, "3", 2},
+		{"quoted key", `"a.b" = 1`, []string{"a.b"}, "1", 1},
+		{"implicit table defined later", "[a.b]\nc = 1\n[a]\nd = 2", []string{"a", "d"}, "2", 4},
+		{"array of tables", "[[a]]\nx = 1\n[[a]]\nx = 2", []string{"a", "1", "x"}, "2", 4},
+		{"subtable of array", "[[a]]\n[a.b]\nc = 1", []string{"a", "0", "b", "c"}, "1", 3},
+		{"array", "a = [\n  1,\n  2,\n]", []string{"a", "1"}, "2", 3},
+		{"inline table", "a = {b = 1, c.d = 2}", []string{"a", "c", "d"}, "2", 1},
+		{"hex", "a = 0xDEAD_beef", []string{"a"}, "0xDEAD_beef", 1},
+		{"float", "a = -3.5e+2", []string{"a"}, "-3.5e+2", 1},
+		{"infinity", "a = -inf", []string{"a"}, "-inf", 1},
+		{"datetime", "a = 1979-05-27 07:32:00Z", []string{"a"}, "1979-05-27 07:32:00Z", 1},
+		{"local time", "a = 07:32:00.5", []string{"a"}, "07:32:00.5", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseTOML([]byte(tt.src))
+			if err != nil {
+				t.Fatalf("parseTOML(%q): %v", tt.src, err)
+			}
+			node := tomlLookup(t, root, tt.path)
+			if node.Value != tt.want || node.Line != tt.line {
+				t.Errorf("%v = %q on line %d, want %q on line %d", tt.path, node.Value, node.Line, tt.want, tt.line)
+			}
+		})
+	}
+}
+
+func TestParseTOMLRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"array of tables over value", "a = 1\n[[a]]"},
+		{"array of tables over table", "[a]\n[[a]]"},
+		{"table over array of tables", "[[a]]\n[a]"},
+		{"table over value", "a = 1\n[a]"},
+		{"table twice", "[a]\n[a]"},
+		{"table over dotted key", "a.b = 1\n[a]"},
+		{"header through value", "a = 1\n[a.b]"},
+		{"header through static array", "a = [{}]\n[a.b]"},
+		{"header through inline table", "a = {}\n[a.b]"},
+		{"dotted key into inline table", "a = {}\na.b = 1"},
+		{"duplicate key", "a = 1\na = 2"},
+		{"duplicate key in inline table", "a = {b = 1, b = 2}"},
+		{"unknown escape", `a = "\q"`},
+		{"short unicode escape", `a = "\u00e"`},
+		{"surrogate escape", `a = "\uD800"`},
+		{"unknown bare value", "a = yes"},
+		{"two values", "a = 1 2"},
+		{"trailing garbage after value", `a = "x" y`},
+		{"trailing garbage after header", "[a] b"},
+		{"missing equals", "a 1"},
+		{"unterminated string", `a = "x`},
+		{"newline in string", "a = \"x\ny\""},
+		{"unclosed header", "[a"},
+		{"unclosed array", "a = [1, 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseTOML([]byte(tt.src)); err == nil {
+				t.Errorf("parseTOML(%q) succeeded, want error", tt.src)
+			}
+		})
+	}
+}
+
+func tomlLookup(t *testing.T, node *yaml.Node, path []string) *yaml.Node {
+	t.Helper()
+	for _, key := range path {
+		switch node.Kind {
+		case yaml.MappingNode:
+			node = tomlChild(node, key)
+		case yaml.SequenceNode:
+			i := int(key[0] - '0')
+			if i >= len(node.Content) {
+				t.Fatalf("index %s out of range", key)
+			}
+			node = node.Content[i]
+		default:
+			node = nil
+		}
+		if node == nil {
+			t.Fatalf("no node at %v", path)
+		}
+	}
+	return node
+}
@@ -0,0 +1,208 @@
+package finder
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type UsageNode struct {
+	Name     string
+	Path     string
+	Size     int64
+	Files    int64
+	IsDir    bool
+	Mount    bool
+	Failed   bool
+	Partial  bool
+	Parent   *UsageNode
+	Children []*UsageNode
+}
+
+type UsageProgress struct {
+	Items   int64
+	Size    int64
+	Current string
+	Done    bool
+}
+
+type usageScanner struct {
+	ctx     context.Context
+	device  uint64
+	sem     chan struct{}
+	links   sync.Map
+	items   atomic.Int64
+	size    atomic.Int64
+	current atomic.Value
+}
+
+func AnalyzeUsage(ctx context.Context, root string, progress func(UsageProgress)) (*UsageNode, error) {
+	root = filepath.Clean(root)
+	info, err := os.Lstat(root)
+	if err != nil {
+		return nil, err
+	}
+	usage, device, _, _ := diskStat(info)
+	node := &UsageNode{Name: root, Path: root, Size: usage, IsDir: info.IsDir()}
+	if !info.IsDir() {
+		node.Files = 1
+		return node, nil
+	}
+
+	scanner := &usageScanner{ctx: ctx, device: device, sem: make(chan struct{}, runtime.NumCPU()*4)}
+	scanner.current.Store(root)
+	stop := make(chan struct{})
+	var reporter sync.WaitGroup
+	if progress != nil {
+		reporter.Add(1)
+		go func() {
+			defer reporter.Done()
+			ticker := time.NewTicker(progressInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					progress(scanner.snapshot(false))
+				case <-stop:
+					progress(scanner.snapshot(true))
+					return
+				}
+			}
+		}()
+	}
+	scanner.dir(node)
+	close(stop)
+	reporter.Wait()
+	if err := ctx.Err(); err != nil {
+		return node, err
+	}
+	return node, nil
+}
+
+func (s *usageScanner) snapshot(done bool) UsageProgress {
+	return UsageProgress{
+		Items:   s.items.Load(),
+		Size:    s.size.Load(),
+		Current: s.current.Load().(string),
+		Done:    done,
+	}
+}
+
+func (s *usageScanner) dir(node *UsageNode) {
+	if s.ctx.Err() != nil {
+		node.Partial = true
+		return
+	}
+	s.current.Store(node.Path)
+	entries, err := os.ReadDir(node.Path)
+	if err != nil {
+		node.Failed = true
+	}
+	var wg sync.WaitGroup
+	children := make([]*UsageNode, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		child := &UsageNode{
+			Name:   entry.Name(),
+			Path:   filepath.Join(node.Path, entry.Name()),
+			IsDir:  info.IsDir(),
+			Parent: node,
+		}
+		usage, device, link, linked := diskStat(info)
+		if linked {
+			if _, seen := s.links.LoadOrStore(link, true); seen {
+				usage = 0
+			}
+		}
+		child.Size = usage
+		s.items.Add(1)
+		s.size.Add(usage)
+		children = append(children, child)
+		switch {
+		case !child.IsDir:
+			child.Files = 1
+		case device != s.device:
+			child.Mount = true
+		default:
+			select {
+			case s.sem <- struct{}{}:
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() { <-s.sem }()
+					s.dir(child)
+				}()
+			default:
+				s.dir(child)
+			}
+		}
+	}
+	wg.Wait()
+	for _, child := range children {
+		node.Size += child.Size
+		node.Files += child.Files
+	}
+	SortUsage(children)
+	node.Children = children
+}
+
+func SortUsage(nodes []*UsageNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Size != nodes[j].Size {
+			return nodes[i].Size > nodes[j].Size
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+}
+
+func (n *UsageNode) Detach() {
+	parent := n.Parent
+	if parent == nil {
+		return
+	}
+	for i, child := range parent.Children {
+		if child == n {
+			parent.Children = append(parent.Children[:i], parent.Children[i+1:]...)
+			break
+		}
+	}
+	for up := parent; up != nil; up = up.Parent {
+		up.Size -= n.Size
+		up.Files -= n.Files
+	}
+	n.Parent = nil
+}
+
+func (n *UsageNode) Rescan(ctx context.Context) error {
+	fresh, err := AnalyzeUsage(ctx, n.Path, nil)
+	if errors.Is(err, fs.ErrNotExist) {
+		n.Detach()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	size, files := fresh.Size-n.Size, fresh.Files-n.Files
+	for _, child := range fresh.Children {
+		child.Parent = n
+	}
+	n.Size, n.Files, n.Failed, n.Partial, n.Children = fresh.Size, fresh.Files, fresh.Failed, fresh.Partial, fresh.Children
+	for up := n.Parent; up != nil; up = up.Parent {
+		up.Size += size
+		up.Files += files
+	}
+	if n.Parent != nil {
+		SortUsage(n.Parent.Children)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package finder
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	WatchCreate   = "create"
+	WatchModify   = "modify"
+	WatchDelete   = "delete"
+	WatchOverflow = "overflow"
+)
+
+type WatchEvent struct {
+	Op   string
+	Path string
+	Time time.Time
+}
+
+const watchSettle = 200 * time.Millisecond
+
+type goneInfo struct {
+	name string
+}
+
+func (g goneInfo) Name() string       { return g.name }
+func (g goneInfo) Size() int64        { return 0 }
+func (g goneInfo) Mode() os.FileMode  { return 0 }
+func (g goneInfo) ModTime() time.Time { return time.Now() }
+func (g goneInfo) IsDir() bool        { return false }
+func (g goneInfo) Sys() any           { return nil }
+
+func newGoneInfo(path string) os.FileInfo {
+	return goneInfo{name: filepath.Base(path)}
+}
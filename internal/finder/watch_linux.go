@@ -0,0 +1,303 @@
+//go:build linux
+
+package finder
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"sakibox/internal/voice"
+)
+
+const watchMask = syscall.IN_CREATE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE | syscall.IN_DELETE |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_ONLYDIR
+
+type inotifyEvent struct {
+	wd   int32
+	mask uint32
+	name string
+}
+
+type pendingEvent struct {
+	op    string
+	first time.Time
+	last  time.Time
+}
+
+type watcher struct {
+	ctx     context.Context
+	fd      int
+	root    string
+	filter  *treeFilter
+	query   *Query
+	handle  func(WatchEvent)
+	dirs    map[int32]string
+	pending map[string]pendingEvent
+	matched map[string]bool
+	seen    time.Time
+}
+
+func Watch(ctx context.Context, root string, query *Query, handle func(WatchEvent)) error {
+	opts, err := loadWalkOptions()
+	if err != nil {
+		return err
+	}
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return err
+	}
+	file := os.NewFile(uintptr(fd), "inotify")
+	defer file.Close()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	root = filepath.Clean(root)
+	w := &watcher{
+		ctx:     ctx,
+		fd:      fd,
+		root:    root,
+		filter:  newTreeFilter(root, opts),
+		query:   query,
+		handle:  handle,
+		dirs:    make(map[int32]string),
+		pending: make(map[string]pendingEvent),
+		matched: make(map[string]bool),
+		seen:    time.Now(),
+	}
+	if err := w.addTree(ctx, root, "", time.Time{}); err != nil {
+		return err
+	}
+
+	events := make(chan []inotifyEvent)
+	failed := make(chan error, 1)
+	go readInotify(ctx, file, events, failed)
+	ticker := time.NewTicker(watchSettle / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-failed:
+			return err
+		case batch := <-events:
+			for _, event := range batch {
+				if event.mask&syscall.IN_Q_OVERFLOW != 0 {
+					if err := w.rescan(ctx); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := w.event(ctx, event); err != nil {
+					return err
+				}
+			}
+		case now := <-ticker.C:
+			w.flush(now)
+		}
+	}
+}
+
+func readInotify(ctx context.Context, file *os.File, events chan<- []inotifyEvent, failed chan<- error) {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := file.Read(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				failed <- err
+			}
+			return
+		}
+		batch := make([]inotifyEvent, 0)
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			length := int(binary.NativeEndian.Uint32(buf[offset+12:]))
+			end := offset + syscall.SizeofInotifyEvent + length
+			batch = append(batch, inotifyEvent{
+				wd:   int32(binary.NativeEndian.Uint32(buf[offset:])),
+				mask: binary.NativeEndian.Uint32(buf[offset+4:]),
+				name: strings.TrimRight(string(buf[offset+syscall.SizeofInotifyEvent:min(end, n)]), "\x00"),
+			})
+			offset = end
+		}
+		select {
+		case events <- batch:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *watcher) addTree(ctx context.Context, dir, op string, since time.Time) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == w.root {
+				return err
+			}
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			if op == "" || !entry.Type().IsRegular() || w.filter.skipFile(path) {
+				return nil
+			}
+			if info, err := entry.Info(); err == nil && !info.ModTime().Before(since) {
+				w.touch(path, op, time.Now())
+			}
+			return nil
+		}
+		if path != w.root && w.filter.skipDir(path, entry.Name()) {
+			return filepath.SkipDir
+		}
+		w.filter.enter(path)
+		wd, err := syscall.InotifyAddWatch(w.fd, path, watchMask)
+		if errors.Is(err, syscall.ENOSPC) {
+			return errors.New(voice.Line("finder_watch_limit"))
+		}
+		if err != nil && path == w.root {
+			return &os.PathError{Op: "watch", Path: path, Err: err}
+		}
+		if err != nil {
+			return filepath.SkipDir
+		}
+		w.dirs[int32(wd)] = path
+		return nil
+	})
+}
+
+func (w *watcher) event(ctx context.Context, event inotifyEvent) error {
+	if event.mask&syscall.IN_IGNORED != 0 {
+		delete(w.dirs, event.wd)
+		return nil
+	}
+	w.seen = time.Now()
+	dir, ok := w.dirs[event.wd]
+	if !ok || event.name == "" {
+		return nil
+	}
+	path := filepath.Join(dir, event.name)
+	now := time.Now()
+	if event.mask&syscall.IN_ISDIR != 0 {
+		if event.mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 && !w.filter.skipDir(path, event.name) {
+			return w.addTree(ctx, path, WatchCreate, time.Time{})
+		}
+		if event.mask&(syscall.IN_DELETE|syscall.IN_MOVED_FROM) != 0 {
+			w.removeTree(path, now)
+		}
+		return nil
+	}
+	if w.filter.skipFile(path) {
+		return nil
+	}
+	switch {
+	case event.mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0:
+		w.touch(path, WatchCreate, now)
+	case event.mask&(syscall.IN_MODIFY|syscall.IN_CLOSE_WRITE) != 0:
+		w.touch(path, WatchModify, now)
+	case event.mask&(syscall.IN_DELETE|syscall.IN_MOVED_FROM) != 0:
+		pending, ok := w.pending[path]
+		delete(w.pending, path)
+		if ok && pending.op == WatchCreate {
+			return nil
+		}
+		if w.matched[path] || w.query.Match(w.ctx, w.root, path, newGoneInfo(path)) {
+			w.handle(WatchEvent{Op: WatchDelete, Path: path, Time: now})
+		}
+		delete(w.matched, path)
+	}
+	return nil
+}
+
+func (w *watcher) removeTree(dir string, now time.Time) {
+	prefix := dir + string(filepath.Separator)
+	for wd, path := range w.dirs {
+		if path == dir || strings.HasPrefix(path, prefix) {
+			syscall.InotifyRmWatch(w.fd, uint32(wd))
+			delete(w.dirs, wd)
+		}
+	}
+	for path := range w.pending {
+		if strings.HasPrefix(path, prefix) {
+			delete(w.pending, path)
+		}
+	}
+	gone := make([]string, 0)
+	for path := range w.matched {
+		if strings.HasPrefix(path, prefix) {
+			gone = append(gone, path)
+		}
+	}
+	sort.Strings(gone)
+	for _, path := range gone {
+		delete(w.matched, path)
+		w.handle(WatchEvent{Op: WatchDelete, Path: path, Time: now})
+	}
+}
+
+func (w *watcher) rescan(ctx context.Context) error {
+	now := time.Now()
+	w.handle(WatchEvent{Op: WatchOverflow, Path: w.root, Time: now})
+	gone := make([]string, 0)
+	for path := range w.matched {
+		if _, err := os.Lstat(path); err != nil {
+			gone = append(gone, path)
+		}
+	}
+	sort.Strings(gone)
+	for _, path := range gone {
+		delete(w.matched, path)
+		w.handle(WatchEvent{Op: WatchDelete, Path: path, Time: now})
+	}
+	for wd, path := range w.dirs {
+		if _, err := os.Lstat(path); err != nil {
+			syscall.InotifyRmWatch(w.fd, uint32(wd))
+			delete(w.dirs, wd)
+		}
+	}
+	since := w.seen.Add(-time.Second)
+	w.seen = now
+	return w.addTree(ctx, w.root, WatchModify, since)
+}
+
+func (w *watcher) touch(path, op string, now time.Time) {
+	pending, ok := w.pending[path]
+	if !ok {
+		pending = pendingEvent{op: op, first: now}
+	}
+	pending.last = now
+	w.pending[path] = pending
+}
+
+func (w *watcher) flush(now time.Time) {
+	ready := make([]string, 0)
+	for path, pending := range w.pending {
+		if now.Sub(pending.last) >= watchSettle || now.Sub(pending.first) >= 5*watchSettle {
+			ready = append(ready, path)
+		}
+	}
+	sort.Slice(ready, func(i, j int) bool {
+		return w.pending[ready[i]].first.Before(w.pending[ready[j]].first)
+	})
+	for _, path := range ready {
+		pending := w.pending[path]
+		delete(w.pending, path)
+		info, err := os.Lstat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if !w.query.Match(w.ctx, w.root, path, info) {
+			delete(w.matched, path)
+			continue
+		}
+		w.matched[path] = true
+		w.handle(WatchEvent{Op: pending.op, Path: path, Time: now})
+	}
+}
@@ -0,0 +1,123 @@
+package finder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func watchEvents(t *testing.T, query string, act func(dir string)) []WatchEvent {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	q, err := ParseQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	events := make([]WatchEvent, 0)
+	done := make(chan error, 1)
+	go func() {
+		done <- Watch(ctx, dir, q, func(event WatchEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		})
+	}()
+	time.Sleep(100 * time.Millisecond)
+	act(dir)
+	time.Sleep(4 * watchSettle)
+	cancel()
+	if err := <-done; err != nil && err != context.Canceled {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return events
+}
+
+func TestWatchDebounce(t *testing.T) {
+	tests := []struct {
+		name string
+		act  func(t *testing.T, dir string)
+		want []string
+	}{
+		{
+			name: "burst of writes is one create",
+			act: func(t *testing.T, dir string) {
+				file, err := os.Create(filepath.Join(dir, "a.log"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer file.Close()
+				for range 5 {
+					file.WriteString("line\n")
+					time.Sleep(watchSettle / 10)
+				}
+			},
+			want: []string{"create a.log"},
+		},
+		{
+			name: "created and removed before settling",
+			act: func(t *testing.T, dir string) {
+				path := filepath.Join(dir, "a.log")
+				if err := os.WriteFile(path, nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Remove(path); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "separate files each reported",
+			act: func(t *testing.T, dir string) {
+				for _, name := range []string{"a.log", "b.log", "c.txt"} {
+					if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+						t.Fatal(err)
+					}
+				}
+			},
+			want: []string{"create a.log", "create b.log"},
+		},
+		{
+			name: "files in a new directory",
+			act: func(t *testing.T, dir string) {
+				sub := filepath.Join(dir, "sub")
+				if err := os.Mkdir(sub, 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(sub, "a.log"), nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: []string{"create sub/a.log"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var root string
+			events := watchEvents(t, "name:*.log", func(dir string) {
+				root = dir
+				tt.act(t, dir)
+			})
+			got := make(map[string]bool)
+			for _, event := range events {
+				rel, _ := filepath.Rel(root, event.Path)
+				got[event.Op+" "+rel] = true
+			}
+			if len(events) != len(tt.want) {
+				t.Errorf("got %d events %v, want %v", len(events), got, tt.want)
+			}
+			for _, want := range tt.want {
+				if !got[want] {
+					t.Errorf("missing event %q in %v", want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package finder
+
+import (
+	"context"
+	"errors"
+
+	"sakibox/internal/voice"
+)
+
+func Watch(ctx context.Context, root string, query *Query, handle func(WatchEvent)) error {
+	return errors.New(voice.Line("finder_watch_unsupported"))
+}
@@ -0,0 +1,365 @@
+package gitsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"sakibox/internal/bookmark"
+	"sakibox/internal/ssh"
+	"sakibox/internal/storage"
+	"sakibox/internal/voice"
+)
+
+type Result struct {
+	Updated   []string
+	Conflicts []string
+	Committed bool
+	Pushed    bool
+}
+
+type syncFile struct {
+	name  string
+	store *storage.Store
+}
+
+var files = []syncFile{
+	{name: bookmark.Store.Name, store: &bookmark.Store},
+	{name: ssh.CommandStore.Name, store: &ssh.CommandStore},
+	{name: "config.yaml"},
+}
+
+var localOnlyConfig = map[string]bool{
+	"history_file": true,
+}
+
+var localOnlyFields = map[string]bool{
+	"use_count": true,
+	"last_used": true,
+}
+
+const maxAttempts = 3
+
+func Dir() (string, error) {
+	return storage.Path("sync")
+}
+
+func Files() []string {
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.name)
+	}
+	return names
+}
+
+func Run(ctx context.Context, remote, branch string) (Result, error) {
+	if strings.TrimSpace(remote) == "" {
+		return Result{}, errors.New(voice.Line("sync_no_remote"))
+	}
+	if branch == "" {
+		branch = "main"
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return Result{}, errors.New(voice.Line("sync_no_git"))
+	}
+	dir, err := Dir()
+	if err != nil {
+		return Result{}, err
+	}
+	unlock, err := storage.Lock(dir, true)
+	if err != nil {
+		return Result{}, err
+	}
+	defer unlock()
+
+	repo := repository{ctx: ctx, dir: dir, branch: branch}
+	if err := repo.ensure(remote); err != nil {
+		return Result{}, err
+	}
+	var result Result
+	for attempt := 1; ; attempt++ {
+		result, err = repo.sync()
+		if err == nil || !errors.Is(err, errRejected) || attempt == maxAttempts {
+			return result, err
+		}
+	}
+}
+
+var errRejected = errors.New("push rejected")
+
+type repository struct {
+	ctx    context.Context
+	dir    string
+	branch string
+}
+
+func (r repository) ensure(remote string) error {
+	if _, err := os.Stat(filepath.Join(r.dir, ".git")); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err := os.MkdirAll(r.dir, 0700); err != nil {
+			return err
+		}
+		if _, err := r.git("init", "-q"); err != nil {
+			return err
+		}
+		if _, err := r.git("symbolic-ref", "HEAD", "refs/heads/"+r.branch); err != nil {
+			return err
+		}
+		_, err := r.git("remote", "add", "origin", remote)
+		return err
+	}
+	current, err := r.git("remote", "get-url", "origin")
+	if err != nil {
+		_, err = r.git("remote", "add", "origin", remote)
+		return err
+	}
+	if current != remote {
+		_, err = r.git("remote", "set-url", "origin", remote)
+	}
+	return err
+}
+
+func (r repository) sync() (Result, error) {
+	result := Result{Updated: []string{}, Conflicts: []string{}}
+	remoteRef := "refs/remotes/origin/" + r.branch
+	heads, err := r.git("ls-remote", "--heads", "origin", r.branch)
+	if err != nil {
+		return result, err
+	}
+	hasRemote := heads != ""
+	if hasRemote {
+		if _, err := r.git("fetch", "-q", "origin", "+refs/heads/"+r.branch+":"+remoteRef); err != nil {
+			return result, err
+		}
+	}
+	hasHead := r.exists("HEAD")
+
+	for _, file := range files {
+		var base, remote []byte
+		if hasHead {
+			base = r.show("HEAD", file.name)
+		}
+		if hasRemote {
+			remote = r.show(remoteRef, file.name)
+		}
+		merged, changed, conflicts, err := mergeFile(file, base, remote)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", file.name, err)
+		}
+		for _, conflict := range conflicts {
+			result.Conflicts = append(result.Conflicts, file.name+": "+conflict)
+		}
+		if changed {
+			result.Updated = append(result.Updated, file.name)
+		}
+		if merged == nil {
+			continue
+		}
+		if err := storage.WriteFileAtomic(filepath.Join(r.dir, file.name), merged, 0600); err != nil {
+			return result, err
+		}
+	}
+
+	if hasRemote {
+		if _, err := r.git("reset", "-q", "--soft", remoteRef); err != nil {
+			return result, err
+		}
+	}
+	if _, err := r.git("add", "-A", "."); err != nil {
+		return result, err
+	}
+	if _, err := r.git("diff", "--cached", "--quiet"); err != nil {
+		host, _ := os.Hostname()
+		message := fmt.Sprintf("sakibox sync from %s at %s", host, time.Now().Format("2006-01-02 15:04:05"))
+		args := []string{"commit", "-q", "-m", message}
+		if email, _ := r.git("config", "user.email"); email == "" {
+			args = append([]string{"-c", "user.name=sakibox", "-c", "user.email=sakibox@localhost"}, args...)
+		}
+		if _, err := r.git(args...); err != nil {
+			return result, err
+		}
+		result.Committed = true
+	}
+	if !r.exists("HEAD") {
+		return result, nil
+	}
+	head, _ := r.git("rev-parse", "HEAD")
+	if hasRemote {
+		if upstream, _ := r.git("rev-parse", remoteRef); upstream == head {
+			return result, nil
+		}
+	}
+	if _, err := r.git("push", "-q", "origin", "HEAD:refs/heads/"+r.branch); err != nil {
+		if strings.Contains(err.Error(), "rejected") || strings.Contains(err.Error(), "fetch first") {
+			return result, errRejected
+		}
+		return result, err
+	}
+	result.Pushed = true
+	return result, nil
+}
+
+func (r repository) exists(ref string) bool {
+	_, err := r.git("rev-parse", "-q", "--verify", ref)
+	return err == nil
+}
+
+func (r repository) show(ref, name string) []byte {
+	output, err := r.git("show", ref+":"+name)
+	if err != nil {
+		return nil
+	}
+	return []byte(output + "\n")
+}
+
+func (r repository) git(args ...string) (string, error) {
+	cmd := exec.CommandContext(r.ctx, "git", append([]string{"-C", r.dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "LC_ALL=C")
+	cmd.WaitDelay = time.Second
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctxErr := r.ctx.Err(); ctxErr != nil {
+			return "", fmt.Errorf("git %s: %w", args[0], ctxErr)
+		}
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			return "", fmt.Errorf("git %s: %w", args[0], err)
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], message)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func mergeFile(file syncFile, base, remote []byte) ([]byte, bool, []string, error) {
+	if file.store == nil {
+		return mergeConfig(file.name, base, remote)
+	}
+	baseData, err := file.store.Decode(base)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	remoteData, err := file.store.Decode(remote)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	var (
+		merged    json.RawMessage
+		conflicts []string
+		changed   bool
+		local     json.RawMessage
+	)
+	err = file.store.Update(&local, func() error {
+		var err error
+		merged, conflicts, err = mergeList(baseData, local, remoteData, localOnlyFields)
+		if err != nil {
+			return err
+		}
+		restored, err := restoreFields(merged, local, localOnlyFields)
+		if err != nil {
+			return err
+		}
+		before, err := canonicalList(local, nil)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(before, restored) {
+			return storage.ErrUnchanged
+		}
+		changed = true
+		local = restored
+		return nil
+	})
+	if err != nil {
+		return nil, false, nil, err
+	}
+	if len(base) == 0 && len(remote) == 0 && string(merged) == "[]" {
+		return nil, false, conflicts, nil
+	}
+	encoded, err := file.store.Encode(merged)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	return append(encoded, '\n'), changed, conflicts, nil
+}
+
+func canonicalList(data json.RawMessage, drop map[string]bool) (json.RawMessage, error) {
+	items, err := keyedItems(data, drop)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		values = append(values, item.value)
+	}
+	return json.Marshal(values)
+}
+
+func mergeConfig(name string, base, remote []byte) ([]byte, bool, []string, error) {
+	path, err := storage.Path(name)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	unlock, err := storage.Lock(path, true)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	defer unlock()
+
+	localData, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil, err
+	}
+	var baseMap, localMap, remoteMap map[string]any
+	for _, pair := range []struct {
+		data   []byte
+		target *map[string]any
+	}{{base, &baseMap}, {localData, &localMap}, {remote, &remoteMap}} {
+		if err := yaml.Unmarshal(pair.data, pair.target); err != nil {
+			return nil, false, nil, err
+		}
+	}
+	if localMap == nil && remoteMap == nil {
+		return nil, false, nil, nil
+	}
+
+	merged, conflicts := mergeMap(baseMap, localMap, remoteMap, localOnlyConfig)
+	data, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	shared := make(map[string]any, len(merged))
+	for key, value := range merged {
+		if !localOnlyConfig[key] {
+			shared[key] = value
+		}
+	}
+	synced, err := yaml.Marshal(shared)
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	localNormalized, err := yaml.Marshal(localMap)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	if bytes.Equal(localNormalized, data) {
+		return synced, false, conflicts, nil
+	}
+	if err := storage.WriteFileAtomic(path, data, 0644); err != nil {
+		return nil, false, nil, err
+	}
+	return synced, true, conflicts, nil
+}
@@ -0,0 +1,202 @@
+package gitsync
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+func mergeList(base, local, remote json.RawMessage, drop map[string]bool) (json.RawMessage, []string, error) {
+	baseItems, err := keyedItems(base, drop)
+	if err != nil {
+		return nil, nil, err
+	}
+	localItems, err := keyedItems(local, drop)
+	if err != nil {
+		return nil, nil, err
+	}
+	remoteItems, err := keyedItems(remote, drop)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	baseByKey := indexItems(baseItems)
+	localByKey := indexItems(localItems)
+	remoteByKey := indexItems(remoteItems)
+
+	merged := make([]json.RawMessage, 0, len(localItems)+len(remoteItems))
+	conflicts := make([]string, 0)
+	pick := func(key string) {
+		b, l, r := baseByKey[key], localByKey[key], remoteByKey[key]
+		value, conflict := merge3(b, l, r)
+		if conflict {
+			conflicts = append(conflicts, key)
+		}
+		if value != nil {
+			merged = append(merged, value)
+		}
+	}
+	for _, item := range localItems {
+		pick(item.key)
+	}
+	for _, item := range remoteItems {
+		if _, ok := localByKey[item.key]; !ok {
+			pick(item.key)
+		}
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, conflicts, nil
+}
+
+func merge3(base, local, remote json.RawMessage) (json.RawMessage, bool) {
+	switch {
+	case bytes.Equal(local, remote):
+		return local, false
+	case bytes.Equal(local, base):
+		return remote, false
+	case bytes.Equal(remote, base):
+		return local, false
+	case local == nil:
+		return remote, true
+	default:
+		return local, true
+	}
+}
+
+type keyedItem struct {
+	key   string
+	value json.RawMessage
+}
+
+func keyedItems(data json.RawMessage, drop map[string]bool) ([]keyedItem, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return []keyedItem{}, nil
+	}
+	var raw []any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	items := make([]keyedItem, 0, len(raw))
+	for _, value := range raw {
+		if fields, ok := value.(map[string]any); ok {
+			for field := range drop {
+				delete(fields, field)
+			}
+		}
+		canonical, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, keyedItem{key: itemKey(value, canonical), value: canonical})
+	}
+	return items, nil
+}
+
+func itemKey(value any, canonical []byte) string {
+	if fields, ok := value.(map[string]any); ok {
+		if name, ok := fields["name"].(string); ok && name != "" {
+			return name
+		}
+	}
+	return string(canonical)
+}
+
+func restoreFields(merged, local json.RawMessage, fields map[string]bool) (json.RawMessage, error) {
+	var localItems, mergedItems []any
+	if len(local) > 0 {
+		if err := json.Unmarshal(local, &localItems); err != nil {
+			return nil, err
+		}
+	}
+	if err := json.Unmarshal(merged, &mergedItems); err != nil {
+		return nil, err
+	}
+	kept := make(map[string]map[string]any)
+	for _, value := range localItems {
+		item, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		saved := make(map[string]any)
+		for field := range fields {
+			if v, ok := item[field]; ok {
+				saved[field] = v
+				delete(item, field)
+			}
+		}
+		canonical, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		if key := itemKey(item, canonical); kept[key] == nil {
+			kept[key] = saved
+		}
+	}
+	for _, value := range mergedItems {
+		item, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		canonical, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		for field, v := range kept[itemKey(item, canonical)] {
+			item[field] = v
+		}
+	}
+	return json.Marshal(mergedItems)
+}
+
+func indexItems(items []keyedItem) map[string]json.RawMessage {
+	index := make(map[string]json.RawMessage, len(items))
+	for _, item := range items {
+		if _, ok := index[item.key]; !ok {
+			index[item.key] = item.value
+		}
+	}
+	return index
+}
+
+func mergeMap(base, local, remote map[string]any, localOnly map[string]bool) (map[string]any, []string) {
+	keys := make(map[string]bool)
+	for _, values := range []map[string]any{base, local, remote} {
+		for key := range values {
+			keys[key] = true
+		}
+	}
+	merged := make(map[string]any)
+	conflicts := make([]string, 0)
+	for key := range keys {
+		b, hasBase := base[key]
+		l, hasLocal := local[key]
+		r, hasRemote := remote[key]
+		if localOnly[key] {
+			if hasLocal {
+				merged[key] = l
+			}
+			continue
+		}
+		switch {
+		case hasLocal == hasRemote && reflect.DeepEqual(l, r):
+		case hasLocal == hasBase && reflect.DeepEqual(l, b):
+			hasLocal, l = hasRemote, r
+		case hasRemote == hasBase && reflect.DeepEqual(r, b):
+		default:
+			conflicts = append(conflicts, key)
+			if !hasLocal {
+				hasLocal, l = hasRemote, r
+			}
+		}
+		if hasLocal {
+			merged[key] = l
+		}
+	}
+	sort.Strings(conflicts)
+	return merged, conflicts
+}
@@ -0,0 +1,177 @@
+package gitsync
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMergeList(t *testing.T) {
+	drop := map[string]bool{"use_count": true}
+	tests := []struct {
+		name      string
+		base      string
+		local     string
+		remote    string
+		want      string
+		conflicts []string
+	}{
+		{
+			name:   "no base takes both sides",
+			local:  `[{"name":"a","cmd":"1"}]`,
+			remote: `[{"name":"b","cmd":"2"}]`,
+			want:   `[{"cmd":"1","name":"a"},{"cmd":"2","name":"b"}]`,
+		},
+		{
+			name:   "remote edit wins over unchanged local",
+			base:   `[{"name":"a","cmd":"1"}]`,
+			local:  `[{"name":"a","cmd":"1"}]`,
+			remote: `[{"name":"a","cmd":"2"}]`,
+			want:   `[{"cmd":"2","name":"a"}]`,
+		},
+		{
+			name:   "local edit wins over unchanged remote",
+			base:   `[{"name":"a","cmd":"1"}]`,
+			local:  `[{"name":"a","cmd":"3"}]`,
+			remote: `[{"name":"a","cmd":"1"}]`,
+			want:   `[{"cmd":"3","name":"a"}]`,
+		},
+		{
+			name:      "both edited keeps local and reports",
+			base:      `[{"name":"a","cmd":"1"}]`,
+			local:     `[{"name":"a","cmd":"3"}]`,
+			remote:    `[{"name":"a","cmd":"2"}]`,
+			want:      `[{"cmd":"3","name":"a"}]`,
+			conflicts: []string{"a"},
+		},
+		{
+			name:   "remote delete of unchanged item",
+			base:   `[{"name":"a","cmd":"1"},{"name":"b","cmd":"2"}]`,
+			local:  `[{"name":"a","cmd":"1"},{"name":"b","cmd":"2"}]`,
+			remote: `[{"name":"b","cmd":"2"}]`,
+			want:   `[{"cmd":"2","name":"b"}]`,
+		},
+		{
+			name:   "local delete of unchanged item",
+			base:   `[{"name":"a","cmd":"1"}]`,
+			local:  `[]`,
+			remote: `[{"name":"a","cmd":"1"}]`,
+			want:   `[]`,
+		},
+		{
+			name:      "local delete of remotely edited item",
+			base:      `[{"name":"a","cmd":"1"}]`,
+			local:     `[]`,
+			remote:    `[{"name":"a","cmd":"2"}]`,
+			want:      `[{"cmd":"2","name":"a"}]`,
+			conflicts: []string{"a"},
+		},
+		{
+			name:   "dropped fields never conflict",
+			base:   `[{"name":"a","cmd":"1","use_count":1}]`,
+			local:  `[{"name":"a","cmd":"1","use_count":5}]`,
+			remote: `[{"name":"a","cmd":"1","use_count":9}]`,
+			want:   `[{"cmd":"1","name":"a"}]`,
+		},
+		{
+			name:   "unnamed items are keyed by content",
+			base:   `["x"]`,
+			local:  `["x","y"]`,
+			remote: `["x","z"]`,
+			want:   `["x","y","z"]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts, err := mergeList(json.RawMessage(tt.base), json.RawMessage(tt.local), json.RawMessage(tt.remote), drop)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(merged) != tt.want {
+				t.Errorf("merged = %s, want %s", merged, tt.want)
+			}
+			if len(conflicts) != 0 || len(tt.conflicts) != 0 {
+				if !reflect.DeepEqual(conflicts, tt.conflicts) {
+					t.Errorf("conflicts = %q, want %q", conflicts, tt.conflicts)
+				}
+			}
+		})
+	}
+}
+
+func TestRestoreFields(t *testing.T) {
+	fields := map[string]bool{"use_count": true, "last_used": true}
+	merged := json.RawMessage(`[{"name":"a","cmd":"2"},{"name":"b","cmd":"3"}]`)
+	local := json.RawMessage(`[{"name":"a","cmd":"1","use_count":4,"last_used":"x"}]`)
+	got, err := restoreFields(merged, local, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"cmd":"2","last_used":"x","name":"a","use_count":4},{"cmd":"3","name":"b"}]`
+	if string(got) != want {
+		t.Errorf("restoreFields = %s, want %s", got, want)
+	}
+}
+
+func TestMergeMap(t *testing.T) {
+	localOnly := map[string]bool{"theme": true}
+	tests := []struct {
+		name      string
+		base      map[string]any
+		local     map[string]any
+		remote    map[string]any
+		want      map[string]any
+		conflicts []string
+	}{
+		{
+			name:   "remote change",
+			base:   map[string]any{"a": 1.0},
+			local:  map[string]any{"a": 1.0},
+			remote: map[string]any{"a": 2.0},
+			want:   map[string]any{"a": 2.0},
+		},
+		{
+			name:   "remote addition and local deletion",
+			base:   map[string]any{"a": 1.0},
+			local:  map[string]any{},
+			remote: map[string]any{"a": 1.0, "b": 2.0},
+			want:   map[string]any{"b": 2.0},
+		},
+		{
+			name:      "both changed",
+			base:      map[string]any{"a": 1.0},
+			local:     map[string]any{"a": 3.0},
+			remote:    map[string]any{"a": 2.0},
+			want:      map[string]any{"a": 3.0},
+			conflicts: []string{"a"},
+		},
+		{
+			name:      "local delete against remote change",
+			base:      map[string]any{"a": 1.0},
+			local:     map[string]any{},
+			remote:    map[string]any{"a": 2.0},
+			want:      map[string]any{"a": 2.0},
+			conflicts: []string{"a"},
+		},
+		{
+			name:   "local only keys ignore remote",
+			base:   map[string]any{"theme": "dark"},
+			local:  map[string]any{},
+			remote: map[string]any{"theme": "light"},
+			want:   map[string]any{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts := mergeMap(tt.base, tt.local, tt.remote, localOnly)
+			if !reflect.DeepEqual(merged, tt.want) {
+				t.Errorf("merged = %v, want %v", merged, tt.want)
+			}
+			if len(conflicts) != 0 || len(tt.conflicts) != 0 {
+				if !reflect.DeepEqual(conflicts, tt.conflicts) {
+					t.Errorf("conflicts = %q, want %q", conflicts, tt.conflicts)
+				}
+			}
+		})
+	}
+}
@@ -5,17 +5,39 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"sakibox/config"
 	"sakibox/internal/voice"
 )
 
 type Entry struct {
-	Command string
+	Command     string
+	Time        time.Time
+	Dir         string
+	ExitCode    int
+	HasExitCode bool
 }
 
 func List() ([]Entry, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	all, err := All()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0)
+	for i := len(all) - 1; i >= 0 && len(entries) < cfg.MaxHistory; i-- {
+		entries = append(entries, all[i])
+	}
+	return entries, nil
+}
+
+func All() ([]Entry, error) {
 	cfg, err := config.Load()
 	if err != nil {
 		return nil, err
@@ -34,18 +56,7 @@ func List() ([]Entry, error) {
 		}
 		return nil, err
 	}
-	entries := make([]Entry, 0)
-	for i := len(lines) - 1; i >= 0 && len(entries) < cfg.MaxHistory; i-- {
-		line := strings.TrimSpace(lines[i])
-		if line == "" {
-			continue
-		}
-		if idx := strings.LastIndex(line, ";"); idx != -1 {
-			line = line[idx+1:]
-		}
-		entries = append(entries, Entry{Command: line})
-	}
-	return entries, nil
+	return parseLines(lines), nil
 }
 
 func Search(keyword string) ([]Entry, error) {
@@ -91,6 +102,63 @@ func readLines(path string) ([]string, error) {
 	return lines, scanner.Err()
 }
 
+func parseLines(lines []string) []Entry {
+	entries := make([]Entry, 0, len(lines))
+	var pending time.Time
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "- cmd: ") {
+			entry := Entry{Command: unescapeFish(strings.TrimPrefix(line, "- cmd: "))}
+			for i+1 < len(lines) && strings.HasPrefix(lines[i+1], "  ") {
+				i++
+				if when, ok := strings.CutPrefix(lines[i], "  when: "); ok {
+					entry.Time = parseUnix(when)
+				}
+			}
+			entries = append(entries, entry)
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if ts := parseUnix(strings.TrimPrefix(line, "#")); !ts.IsZero() {
+				pending = ts
+				continue
+			}
+		}
+		for strings.HasSuffix(line, "\\") && i+1 < len(lines) {
+			i++
+			line = strings.TrimSuffix(line, "\\") + "\n" + lines[i]
+		}
+		entry := Entry{Time: pending}
+		pending = time.Time{}
+		if strings.HasPrefix(line, ": ") {
+			if idx := strings.Index(line, ";"); idx != -1 {
+				meta := strings.SplitN(strings.TrimPrefix(line[:idx], ": "), ":", 2)
+				entry.Time = parseUnix(meta[0])
+				line = line[idx+1:]
+			}
+		}
+		entry.Command = strings.TrimSpace(line)
+		if entry.Command == "" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func parseUnix(value string) time.Time {
+	seconds, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+func unescapeFish(value string) string {
+	value = strings.ReplaceAll(value, "\\n", "\n")
+	return strings.ReplaceAll(value, "\\\\", "\\")
+}
+
 func resolveHistoryFile(primary string) (string, error) {
 	if primary != "" {
 		if _, err := os.Stat(primary); err == nil {
@@ -0,0 +1,158 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"sakibox/config"
+	"sakibox/internal/storage"
+)
+
+type Finding struct {
+	File    string
+	Line    int
+	Kind    string
+	Preview string
+}
+
+type secretRule struct {
+	kind    string
+	pattern *regexp.Regexp
+}
+
+const secretMask = "****"
+
+var secretRules = []secretRule{
+	{kind: "aws-access-key", pattern: regexp.MustCompile(`\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`)},
+	{kind: "aws-secret-key", pattern: regexp.MustCompile(`(?i)aws_secret_access_key\s*[=:]\s*["']?([A-Za-z0-9/+=]{40})`)},
+	{kind: "github-token", pattern: regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{kind: "gitlab-token", pattern: regexp.MustCompile(`\b(glpat-[A-Za-z0-9_-]{20,})`)},
+	{kind: "slack-token", pattern: regexp.MustCompile(`\b(xox[abprs]-[A-Za-z0-9-]{10,})`)},
+	{kind: "authorization", pattern: regexp.MustCompile(`(?i)authorization:\s*(?:bearer\s+|basic\s+|token\s+)?([^\s"']+)`)},
+	{kind: "url-credentials", pattern: regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.-]*://[^/\s:@]+:([^/\s@]+)@`)},
+	{kind: "password-flag", pattern: regexp.MustCompile(`\bsshpass\s+-p\s*["']?([^\s"'$][^\s"']*)`)},
+	{kind: "password-flag", pattern: regexp.MustCompile(`\b(?:mysql|mysqldump|mysqladmin|mariadb)\b[^|;&]*?\s-p["']?([^\s"'$][^\s"']*)`)},
+	{kind: "password-flag", pattern: regexp.MustCompile(`\bredis-cli\b[^|;&]*?\s-a\s+["']?([^\s"'$][^\s"']*)`)},
+	{kind: "password-flag", pattern: regexp.MustCompile(`--password[=\s]+["']?([^\s"'$][^\s"']*)`)},
+	{kind: "secret-assignment", pattern: regexp.MustCompile(`(?i)\b[A-Za-z0-9_]*(?:password|passwd|secret|token|api[_-]?key|access[_-]?key)\s*[=:]\s*["']?([^\s"'&;$][^\s"'&;]{3,})`)},
+}
+
+func Files() ([]string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := defaultHistoryCandidates()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.HistoryFile != "" {
+		candidates = append([]string{cfg.HistoryFile}, candidates...)
+	}
+	files := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		path, err := filepath.Abs(candidate)
+		if err != nil || seen[path] {
+			continue
+		}
+		seen[path] = true
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+func ScanSecrets() ([]Finding, error) {
+	files, err := Files()
+	if err != nil {
+		return nil, err
+	}
+	findings := make([]Finding, 0)
+	for _, path := range files {
+		lines, err := readLines(path)
+		if err != nil {
+			return nil, err
+		}
+		for i, line := range lines {
+			masked, kinds := maskSecrets(line)
+			for _, kind := range kinds {
+				findings = append(findings, Finding{File: path, Line: i + 1, Kind: kind, Preview: strings.TrimSpace(masked)})
+			}
+		}
+	}
+	return findings, nil
+}
+
+func Redact(path string) (string, int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", 0, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0, err
+	}
+	lines := strings.Split(string(data), "\n")
+	count := 0
+	for i, line := range lines {
+		masked, kinds := maskSecrets(line)
+		if len(kinds) > 0 {
+			lines[i] = masked
+			count++
+		}
+	}
+	if count == 0 {
+		return "", 0, nil
+	}
+
+	backup := fmt.Sprintf("%s.sakibox-%s.bak", path, time.Now().Format("20060102-150405"))
+	if err := os.WriteFile(backup, data, 0600); err != nil {
+		return "", 0, err
+	}
+	if err := storage.WriteFileAtomic(path, []byte(strings.Join(lines, "\n")), info.Mode().Perm()); err != nil {
+		return "", 0, err
+	}
+	return backup, count, nil
+}
+
+func maskSecrets(line string) (string, []string) {
+	kinds := make([]string, 0)
+	for _, rule := range secretRules {
+		matches := rule.pattern.FindAllStringSubmatchIndex(line, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		found := false
+		for i := len(matches) - 1; i >= 0; i-- {
+			start, end := matches[i][2], matches[i][3]
+			if start < 0 || line[start:end] == secretMask {
+				continue
+			}
+			line = line[:start] + secretMask + line[end:]
+			found = true
+		}
+		if found {
+			kinds = append(kinds, rule.kind)
+		}
+	}
+	sort.Strings(kinds)
+	return line, uniqueStrings(kinds)
+}
+
+func uniqueStrings(items []string) []string {
+	result := make([]string, 0, len(items))
+	for i, item := range items {
+		if i > 0 && items[i-1] == item {
+			continue
+		}
+		result = append(result, item)
+	}
+	return result
+}
@@ -0,0 +1,175 @@
+package history
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Count struct {
+	Name  string
+	Count int
+}
+
+type Failure struct {
+	Command  string
+	Runs     int
+	Failures int
+}
+
+type Stats struct {
+	Total       int
+	Timed       int
+	First       time.Time
+	Last        time.Time
+	TopCommands []Count
+	TopTools    []Count
+	TopDirs     []Count
+	Weekly      []Count
+	Failures    []Failure
+	Heatmap     [7][24]int
+}
+
+const statsWeeks = 12
+
+func Analyze(entries []Entry, limit int) Stats {
+	stats := Stats{Total: len(entries)}
+	commands := make(map[string]int)
+	tools := make(map[string]int)
+	dirs := make(map[string]int)
+	runs := make(map[string]*Failure)
+
+	for _, entry := range entries {
+		commands[entry.Command]++
+		if tool := commandTool(entry.Command); tool != "" {
+			tools[tool]++
+		}
+		if entry.Dir != "" {
+			dirs[entry.Dir]++
+		} else if dir, ok := cdTarget(entry.Command); ok {
+			dirs[dir]++
+		}
+		if entry.HasExitCode {
+			item, ok := runs[entry.Command]
+			if !ok {
+				item = &Failure{Command: entry.Command}
+				runs[entry.Command] = item
+			}
+			item.Runs++
+			if entry.ExitCode != 0 {
+				item.Failures++
+			}
+		}
+		if entry.Time.IsZero() {
+			continue
+		}
+		stats.Timed++
+		if stats.First.IsZero() || entry.Time.Before(stats.First) {
+			stats.First = entry.Time
+		}
+		if entry.Time.After(stats.Last) {
+			stats.Last = entry.Time
+		}
+		stats.Heatmap[weekdayIndex(entry.Time)][entry.Time.Hour()]++
+	}
+
+	stats.TopCommands = topCounts(commands, limit)
+	stats.TopTools = topCounts(tools, limit)
+	stats.TopDirs = topCounts(dirs, limit)
+	stats.Weekly = weeklyCounts(entries, stats.Last)
+
+	for _, item := range runs {
+		if item.Failures > 0 {
+			stats.Failures = append(stats.Failures, *item)
+		}
+	}
+	sort.Slice(stats.Failures, func(i, j int) bool {
+		a, b := stats.Failures[i], stats.Failures[j]
+		if a.Failures != b.Failures {
+			return a.Failures > b.Failures
+		}
+		return a.Failures*b.Runs > b.Failures*a.Runs
+	})
+	if len(stats.Failures) > limit {
+		stats.Failures = stats.Failures[:limit]
+	}
+	return stats
+}
+
+func commandTool(command string) string {
+	fields := strings.Fields(command)
+	for len(fields) > 0 {
+		field := fields[0]
+		if strings.Contains(field, "=") && !strings.HasPrefix(field, "=") {
+			fields = fields[1:]
+			continue
+		}
+		switch field {
+		case "sudo", "time", "nohup", "exec", "command", "builtin", "env":
+			fields = fields[1:]
+			continue
+		}
+		return filepath.Base(field)
+	}
+	return ""
+}
+
+func cdTarget(command string) (string, bool) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 || (fields[0] != "cd" && fields[0] != "pushd" && fields[0] != "z") {
+		return "", false
+	}
+	if len(fields) == 1 {
+		return "~", true
+	}
+	target := fields[1]
+	if target == "-" {
+		return "", false
+	}
+	if target != "/" {
+		target = strings.TrimSuffix(target, "/")
+	}
+	return target, true
+}
+
+func weekdayIndex(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
+
+func weeklyCounts(entries []Entry, last time.Time) []Count {
+	if last.IsZero() {
+		return nil
+	}
+	end := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, last.Location())
+	end = end.AddDate(0, 0, 7-weekdayIndex(end))
+	start := end.AddDate(0, 0, -7*statsWeeks)
+	weeks := make([]Count, statsWeeks)
+	for i := range weeks {
+		weeks[i].Name = start.AddDate(0, 0, 7*i).Format("2006-01-02")
+	}
+	for _, entry := range entries {
+		if entry.Time.IsZero() || entry.Time.Before(start) || !entry.Time.Before(end) {
+			continue
+		}
+		weeks[int(entry.Time.Sub(start).Hours()/24)/7].Count++
+	}
+	return weeks
+}
+
+func topCounts(counts map[string]int, limit int) []Count {
+	items := make([]Count, 0, len(counts))
+	for name, count := range counts {
+		items = append(items, Count{Name: name, Count: count})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+		return items[i].Name < items[j].Name
+	})
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,90 @@
+package history
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCommandTool(t *testing.T) {
+	tests := map[string]string{
+		"git status":                 "git",
+		"sudo apt update":            "apt",
+		"FOO=1 BAR=2 make build":     "make",
+		"env GOOS=linux go build":    "go",
+		"time nohup ./run.sh":        "run.sh",
+		"/usr/local/bin/kubectl get": "kubectl",
+		"sudo":                       "",
+		"":                           "",
+	}
+	for command, want := range tests {
+		if got := commandTool(command); got != want {
+			t.Errorf("commandTool(%q) = %q, want %q", command, got, want)
+		}
+	}
+}
+
+func TestCdTarget(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+		ok      bool
+	}{
+		{"cd", "~", true},
+		{"cd /srv/app/", "/srv/app", true},
+		{"cd /", "/", true},
+		{"pushd src", "src", true},
+		{"z proj", "proj", true},
+		{"cd -", "", false},
+		{"ls /tmp", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := cdTarget(tt.command)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("cdTarget(%q) = %q, %v, want %q, %v", tt.command, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	monday := time.Date(2024, 3, 4, 9, 30, 0, 0, time.Local)
+	entries := []Entry{
+		{Command: "git status", Time: monday},
+		{Command: "git status", Time: monday.Add(time.Hour)},
+		{Command: "cd /srv/app/", Time: monday.AddDate(0, 0, 2)},
+		{Command: "make test", Dir: "/srv/app", HasExitCode: true, ExitCode: 2, Time: monday.AddDate(0, 0, 7)},
+		{Command: "make test", Dir: "/srv/app", HasExitCode: true},
+		{Command: "go build", HasExitCode: true},
+		{Command: "ls"},
+	}
+	stats := Analyze(entries, 2)
+	if stats.Total != 7 || stats.Timed != 4 {
+		t.Errorf("Total, Timed = %d, %d, want 7, 4", stats.Total, stats.Timed)
+	}
+	if !stats.First.Equal(monday) || !stats.Last.Equal(monday.AddDate(0, 0, 7)) {
+		t.Errorf("First, Last = %v, %v", stats.First, stats.Last)
+	}
+	if want := []Count{{"git status", 2}, {"make test", 2}}; !reflect.DeepEqual(stats.TopCommands, want) {
+		t.Errorf("TopCommands = %v, want %v", stats.TopCommands, want)
+	}
+	if want := []Count{{"git", 2}, {"make", 2}}; !reflect.DeepEqual(stats.TopTools, want) {
+		t.Errorf("TopTools = %v, want %v", stats.TopTools, want)
+	}
+	if want := []Count{{"/srv/app", 3}}; !reflect.DeepEqual(stats.TopDirs, want) {
+		t.Errorf("TopDirs = %v, want %v", stats.TopDirs, want)
+	}
+	if want := []Failure{{Command: "make test", Runs: 2, Failures: 1}}; !reflect.DeepEqual(stats.Failures, want) {
+		t.Errorf("Failures = %v, want %v", stats.Failures, want)
+	}
+	if stats.Heatmap[0][9] != 2 || stats.Heatmap[0][10] != 1 || stats.Heatmap[2][9] != 1 {
+		t.Errorf("Heatmap Monday 9h, 10h, Wednesday 9h = %d, %d, %d, want 2, 1, 1",
+			stats.Heatmap[0][9], stats.Heatmap[0][10], stats.Heatmap[2][9])
+	}
+	if len(stats.Weekly) != statsWeeks {
+		t.Fatalf("got %d weeks, want %d", len(stats.Weekly), statsWeeks)
+	}
+	last, previous := stats.Weekly[statsWeeks-1], stats.Weekly[statsWeeks-2]
+	if last.Name != "2024-03-11" || last.Count != 1 || previous.Name != "2024-03-04" || previous.Count != 3 {
+		t.Errorf("last two weeks = %v, %v, want {2024-03-04 3}, {2024-03-11 1}", previous, last)
+	}
+}
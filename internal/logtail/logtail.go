@@ -0,0 +1,209 @@
+package logtail
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type Line struct {
+	Path string
+	Text string
+}
+
+type Level int
+
+const (
+	LevelNone Level = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+const (
+	pollInterval = 250 * time.Millisecond
+	backlogBytes = 64 * 1024
+	maxLineBytes = 64 * 1024
+)
+
+var levelPattern = regexp.MustCompile(`\b(?i:(fatal|panic|crit(?:ical)?|err(?:or)?|warn(?:ing)?|info|debug|trace))\b`)
+
+func LevelOf(text string) Level {
+	match := levelPattern.FindStringSubmatch(text)
+	if match == nil {
+		return LevelNone
+	}
+	switch strings.ToLower(match[1])[:1] {
+	case "f", "p", "c", "e":
+		return LevelError
+	case "w":
+		return LevelWarn
+	case "i":
+		return LevelInfo
+	}
+	return LevelDebug
+}
+
+func Follow(ctx context.Context, paths []string, backlog int) <-chan Line {
+	lines := make(chan Line, 256)
+	go func() {
+		defer close(lines)
+		tails := make([]*tail, 0, len(paths))
+		for _, path := range paths {
+			t := &tail{path: path}
+			t.open(backlog)
+			tails = append(tails, t)
+		}
+		defer func() {
+			for _, t := range tails {
+				t.close()
+			}
+		}()
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			for _, t := range tails {
+				for _, text := range t.poll() {
+					select {
+					case lines <- Line{Path: t.path, Text: text}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return lines
+}
+
+func Last(path string, n int) []string {
+	t := &tail{path: path}
+	t.open(n)
+	t.close()
+	lines := t.pending
+	if len(t.partial) > 0 {
+		lines = append(lines, string(t.partial))
+	}
+	return lines[max(len(lines)-n, 0):]
+}
+
+type tail struct {
+	path    string
+	file    *os.File
+	info    os.FileInfo
+	offset  int64
+	partial []byte
+	pending []string
+}
+
+func (t *tail) open(backlog int) {
+	file, err := os.Open(t.path)
+	if err != nil {
+		return
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return
+	}
+	t.file, t.info, t.offset, t.partial = file, info, 0, nil
+	if backlog < 0 {
+		return
+	}
+	t.offset = info.Size()
+	if backlog == 0 {
+		return
+	}
+	start := max(info.Size()-backlogBytes, 0)
+	data := make([]byte, info.Size()-start)
+	if _, err := file.ReadAt(data, start); err != nil && err != io.EOF {
+		return
+	}
+	if start > 0 {
+		if cut := bytes.IndexByte(data, '\n'); cut >= 0 {
+			data = data[cut+1:]
+		}
+	}
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		t.partial = append(t.partial, data[i+1:]...)
+		data = data[:i]
+	} else {
+		t.partial, data = append(t.partial, data...), nil
+	}
+	if len(data) > 0 {
+		all := strings.Split(string(data), "\n")
+		t.pending = all[max(len(all)-backlog, 0):]
+	}
+}
+
+func (t *tail) close() {
+	if t.file != nil {
+		_ = t.file.Close()
+		t.file = nil
+	}
+}
+
+func (t *tail) poll() []string {
+	lines := t.pending
+	t.pending = nil
+	if t.file == nil {
+		t.open(-1)
+		if t.file == nil {
+			return lines
+		}
+	}
+	current, err := os.Stat(t.path)
+	rotated := err == nil && !os.SameFile(current, t.info)
+	if err == nil && !rotated && current.Size() < t.offset {
+		t.offset, t.partial = 0, nil
+	}
+	lines = append(lines, t.read()...)
+	if rotated {
+		if len(t.partial) > 0 {
+			lines = append(lines, string(t.partial))
+		}
+		t.close()
+		t.open(-1)
+		lines = append(lines, t.read()...)
+	}
+	return lines
+}
+
+func (t *tail) read() []string {
+	if t.file == nil {
+		return nil
+	}
+	lines := make([]string, 0)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := t.file.ReadAt(buf, t.offset)
+		t.offset += int64(n)
+		data := append(t.partial, buf[:n]...)
+		for {
+			i := bytes.IndexByte(data, '\n')
+			if i < 0 {
+				break
+			}
+			lines = append(lines, strings.TrimRight(string(data[:i]), "\r"))
+			data = data[i+1:]
+		}
+		if len(data) > maxLineBytes {
+			lines = append(lines, string(data))
+			data = nil
+		}
+		t.partial = append([]byte(nil), data...)
+		if err != nil || n < len(buf) {
+			return lines
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package logtail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func appendFile(t *testing.T, path, text string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func expectPoll(t *testing.T, tl *tail, want ...string) {
+	t.Helper()
+	got := tl.poll()
+	if len(got) == 0 && len(want) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("poll = %q, want %q", got, want)
+	}
+}
+
+func TestTailAppendAndPartial(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "1\n2\n3\n4\npart")
+	tl := &tail{path: path}
+	tl.open(2)
+	defer tl.close()
+	expectPoll(t, tl, "3", "4")
+	expectPoll(t, tl)
+	appendFile(t, path, "ial\r\n5\n")
+	expectPoll(t, tl, "partial", "5")
+}
+
+func TestTailTruncate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "old line one\nold line two\n")
+	tl := &tail{path: path}
+	tl.open(0)
+	defer tl.close()
+	expectPoll(t, tl)
+	if err := os.WriteFile(path, []byte("new\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	expectPoll(t, tl, "new")
+}
+
+func TestTailRotate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendFile(t, path, "a\n")
+	tl := &tail{path: path}
+	tl.open(0)
+	defer tl.close()
+	appendFile(t, path, "b\nunfinished")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path, "c\n")
+	expectPoll(t, tl, "b", "unfinished", "c")
+	appendFile(t, path, "d\n")
+	expectPoll(t, tl, "d")
+}
+
+func TestTailMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "later.log")
+	tl := &tail{path: path}
+	tl.open(10)
+	defer tl.close()
+	expectPoll(t, tl)
+	appendFile(t, path, "first\nsecond\n")
+	expectPoll(t, tl, "first", "second")
+}
+
+func TestLast(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "1\n2\n3\ntail")
+	if got, want := Last(path, 2), []string{"3", "tail"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Last = %q, want %q", got, want)
+	}
+	if got := Last(filepath.Join(t.TempDir(), "missing"), 5); len(got) != 0 {
+		t.Errorf("Last of a missing file = %q, want nothing", got)
+	}
+}
+
+func TestFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "old\n")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	lines := Follow(ctx, []string{path}, 1)
+	if line := <-lines; line.Text != "old" || line.Path != path {
+		t.Fatalf("first line = %+v, want the backlog", line)
+	}
+	appendFile(t, path, "new\n")
+	if line := <-lines; line.Text != "new" {
+		t.Fatalf("next line = %+v, want the appended line", line)
+	}
+	cancel()
+	for range lines {
+	}
+}
+
+func TestLevelOf(t *testing.T) {
+	tests := map[string]Level{
+		"2024-01-01 ERROR failed to connect": LevelError,
+		`level=error msg="boom"`:             LevelError,
+		"panic: runtime error":               LevelError,
+		"[WARN] disk almost full":            LevelWarn,
+		"INFO started":                       LevelInfo,
+		"debug: value=1":                     LevelDebug,
+		"TRACE enter":                        LevelDebug,
+		"no level here":                      LevelNone,
+		"errors are not words":               LevelNone,
+	}
+	for text, want := range tests {
+		if got := LevelOf(text); got != want {
+			t.Errorf("LevelOf(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
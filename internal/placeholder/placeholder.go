@@ -0,0 +1,62 @@
+package placeholder
+
+import (
+	"regexp"
+	"strings"
+
+	"sakibox/internal/storage"
+)
+
+type Field struct {
+	Name    string
+	Default string
+}
+
+var pattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*(?::([^}]*))?\}\}`)
+
+func Parse(command string) []Field {
+	fields := make([]Field, 0)
+	seen := make(map[string]bool)
+	for _, match := range pattern.FindAllStringSubmatch(command, -1) {
+		name := match[1]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		fields = append(fields, Field{Name: name, Default: strings.TrimSpace(match[2])})
+	}
+	return fields
+}
+
+func Fill(command string, values map[string]string) string {
+	return pattern.ReplaceAllStringFunc(command, func(token string) string {
+		match := pattern.FindStringSubmatch(token)
+		if value, ok := values[match[1]]; ok {
+			return value
+		}
+		return strings.TrimSpace(match[2])
+	})
+}
+
+var store = storage.Store{Name: "placeholders.json", Version: 1}
+
+func LastValues() (map[string]string, error) {
+	values := make(map[string]string)
+	if err := store.Load(&values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func Remember(values map[string]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	stored := make(map[string]string)
+	return store.Update(&stored, func() error {
+		for name, value := range values {
+			stored[name] = value
+		}
+		return nil
+	})
+}
@@ -0,0 +1,189 @@
+package runlog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"sakibox/internal/storage"
+	"sakibox/internal/voice"
+)
+
+type Run struct {
+	ID         string    `json:"id"`
+	Source     string    `json:"source"`
+	Name       string    `json:"name,omitempty"`
+	Command    string    `json:"command"`
+	Dir        string    `json:"dir"`
+	Start      time.Time `json:"start"`
+	DurationMs int64     `json:"duration_ms"`
+	ExitCode   int       `json:"exit_code"`
+	Error      string    `json:"error,omitempty"`
+	OutputPath string    `json:"output,omitempty"`
+}
+
+type Spec struct {
+	Source  string
+	Name    string
+	Command string
+	Dir     string
+	Env     []string
+	Capture bool
+}
+
+const maxRuns = 200
+
+func (r Run) Duration() time.Duration {
+	return time.Duration(r.DurationMs) * time.Millisecond
+}
+
+var store = storage.Store{Name: "runs.json", Version: 1, Backups: -1}
+
+func Exec(spec Spec) (Run, error) {
+	start := time.Now()
+	run := Run{
+		ID:      strconv.FormatInt(start.UnixNano(), 36),
+		Source:  spec.Source,
+		Name:    spec.Name,
+		Command: spec.Command,
+		Dir:     spec.Dir,
+		Start:   start,
+	}
+	if run.Dir == "" {
+		if wd, err := os.Getwd(); err == nil {
+			run.Dir = wd
+		}
+	}
+
+	cmd := exec.Command("/bin/sh", "-c", spec.Command)
+	cmd.Dir = run.Dir
+	if len(spec.Env) > 0 {
+		cmd.Env = append(os.Environ(), spec.Env...)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if spec.Capture {
+		dir, err := storage.Path("runs")
+		if err != nil {
+			return run, err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return run, err
+		}
+		run.OutputPath = filepath.Join(dir, run.ID+".log")
+		file, err := os.Create(run.OutputPath)
+		if err != nil {
+			return run, err
+		}
+		defer file.Close()
+		shared := &lockedWriter{w: file}
+		cmd.Stdout = io.MultiWriter(os.Stdout, shared)
+		cmd.Stderr = io.MultiWriter(os.Stderr, shared)
+	}
+
+	err := cmd.Run()
+	run.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			run.ExitCode = exitErr.ExitCode()
+		} else {
+			run.ExitCode = -1
+		}
+		run.Error = err.Error()
+	}
+	if saveErr := Add(run); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	return run, err
+}
+
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+func List() ([]Run, error) {
+	items := make([]Run, 0)
+	if err := store.Load(&items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func Recent() ([]Run, error) {
+	items, err := List()
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+	return items, nil
+}
+
+func Add(run Run) error {
+	items := make([]Run, 0)
+	return store.Update(&items, func() error {
+		items = append(items, run)
+		if len(items) > maxRuns {
+			for _, old := range items[:len(items)-maxRuns] {
+				if old.OutputPath != "" {
+					_ = os.Remove(old.OutputPath)
+				}
+			}
+			items = items[len(items)-maxRuns:]
+		}
+		return nil
+	})
+}
+
+func Get(index int) (Run, error) {
+	items, err := Recent()
+	if err != nil {
+		return Run{}, err
+	}
+	if index <= 0 || index > len(items) {
+		return Run{}, errors.New(voice.Line("invalid_index"))
+	}
+	return items[index-1], nil
+}
+
+func ReadOutput(run Run) (string, error) {
+	if run.OutputPath == "" {
+		return "", errors.New(voice.Line("run_no_output"))
+	}
+	data, err := os.ReadFile(run.OutputPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", errors.New(voice.Line("run_no_output"))
+		}
+		return "", err
+	}
+	return string(data), nil
+}
+
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	default:
+		return d.Round(time.Second).String()
+	}
+}
@@ -1,14 +1,12 @@
 package ssh
 
 import (
-	"encoding/json"
 	"errors"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
+	"sakibox/internal/storage"
 	"sakibox/internal/voice"
 )
 
@@ -34,101 +32,15 @@ type Command struct {
 	Command string `json:"command"`
 }
 
-func dataPath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(home, ".sakibox", "ssh.json"), nil
-}
-
-func logPath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(home, ".sakibox", "ssh_logs.json"), nil
-}
-
-func commandPath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(home, ".sakibox", "ssh_commands.json"), nil
-}
-
-func ensureData() error {
-	path, err := dataPath()
-	if err != nil {
-		return err
-	}
-	if _, err := os.Stat(path); err == nil {
-		return nil
-	}
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return err
-	}
-	data, err := json.MarshalIndent([]Server{}, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(path, data, 0644)
-}
-
-func ensureLogs() error {
-	path, err := logPath()
-	if err != nil {
-		return err
-	}
-	if _, err := os.Stat(path); err == nil {
-		return nil
-	}
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return err
-	}
-	data, err := json.MarshalIndent([]LogEntry{}, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(path, data, 0644)
-}
-
-func ensureCommands() error {
-	path, err := commandPath()
-	if err != nil {
-		return err
-	}
-	if _, err := os.Stat(path); err == nil {
-		return nil
-	}
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return err
-	}
-	data, err := json.MarshalIndent([]Command{}, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(path, data, 0644)
-}
+var (
+	store        = storage.Store{Name: "ssh.json", Version: 1, Perm: 0600}
+	logStore     = storage.Store{Name: "ssh_logs.json", Version: 1, Backups: -1}
+	CommandStore = storage.Store{Name: "ssh_commands.json", Version: 1}
+)
 
 func List() ([]Server, error) {
-	if err := ensureData(); err != nil {
-		return nil, err
-	}
-	path, err := dataPath()
-	if err != nil {
-		return nil, err
-	}
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
 	items := make([]Server, 0)
-	if len(data) == 0 {
-		return items, nil
-	}
-	if err := json.Unmarshal(data, &items); err != nil {
+	if err := store.Load(&items); err != nil {
 		return nil, err
 	}
 	return items, nil
@@ -141,12 +53,11 @@ func Add(server Server) error {
 	if server.Port <= 0 {
 		return errors.New(voice.Line("ssh_invalid_port"))
 	}
-	items, err := List()
-	if err != nil {
-		return err
-	}
-	items = append(items, server)
-	return save(items)
+	items := make([]Server, 0)
+	return store.Update(&items, func() error {
+		items = append(items, server)
+		return nil
+	})
 }
 
 func Get(input string) (Server, error) {
@@ -169,94 +80,35 @@ func Get(input string) (Server, error) {
 }
 
 func Delete(index int) error {
-	items, err := List()
-	if err != nil {
-		return err
-	}
-	if index <= 0 || index > len(items) {
-		return errors.New(voice.Line("invalid_index"))
-	}
-	items = append(items[:index-1], items[index:]...)
-	return save(items)
-}
-
-func save(items []Server) error {
-	path, err := dataPath()
-	if err != nil {
-		return err
-	}
-	data, err := json.MarshalIndent(items, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(path, data, 0644)
+	items := make([]Server, 0)
+	return store.Update(&items, func() error {
+		if index <= 0 || index > len(items) {
+			return errors.New(voice.Line("invalid_index"))
+		}
+		items = append(items[:index-1], items[index:]...)
+		return nil
+	})
 }
 
 func AddLog(entry LogEntry) error {
-	if err := ensureLogs(); err != nil {
-		return err
-	}
-	path, err := logPath()
-	if err != nil {
-		return err
-	}
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return err
-	}
 	items := make([]LogEntry, 0)
-	if len(data) > 0 {
-		if err := json.Unmarshal(data, &items); err != nil {
-			return err
-		}
-	}
-	items = append(items, entry)
-	payload, err := json.MarshalIndent(items, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(path, payload, 0644)
+	return logStore.Update(&items, func() error {
+		items = append(items, entry)
+		return nil
+	})
 }
 
 func ListLogs() ([]LogEntry, error) {
-	if err := ensureLogs(); err != nil {
-		return nil, err
-	}
-	path, err := logPath()
-	if err != nil {
-		return nil, err
-	}
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
 	items := make([]LogEntry, 0)
-	if len(data) == 0 {
-		return items, nil
-	}
-	if err := json.Unmarshal(data, &items); err != nil {
+	if err := logStore.Load(&items); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
 
 func ListCommands() ([]Command, error) {
-	if err := ensureCommands(); err != nil {
-		return nil, err
-	}
-	path, err := commandPath()
-	if err != nil {
-		return nil, err
-	}
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
 	items := make([]Command, 0)
-	if len(data) == 0 {
-		return items, nil
-	}
-	if err := json.Unmarshal(data, &items); err != nil {
+	if err := CommandStore.Load(&items); err != nil {
 		return nil, err
 	}
 	return items, nil
@@ -266,12 +118,11 @@ func AddCommand(item Command) error {
 	if strings.TrimSpace(item.Name) == "" || strings.TrimSpace(item.Command) == "" {
 		return errors.New(voice.Line("ssh_cmd_invalid_input"))
 	}
-	items, err := ListCommands()
-	if err != nil {
-		return err
-	}
-	items = append(items, item)
-	return saveCommands(items)
+	items := make([]Command, 0)
+	return CommandStore.Update(&items, func() error {
+		items = append(items, item)
+		return nil
+	})
 }
 
 func GetCommand(input string) (Command, error) {
@@ -294,27 +145,14 @@ func GetCommand(input string) (Command, error) {
 }
 
 func DeleteCommand(index int) error {
-	items, err := ListCommands()
-	if err != nil {
-		return err
-	}
-	if index <= 0 || index > len(items) {
-		return errors.New(voice.Line("invalid_index"))
-	}
-	items = append(items[:index-1], items[index:]...)
-	return saveCommands(items)
-}
-
-func saveCommands(items []Command) error {
-	path, err := commandPath()
-	if err != nil {
-		return err
-	}
-	data, err := json.MarshalIndent(items, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(path, data, 0644)
+	items := make([]Command, 0)
+	return CommandStore.Update(&items, func() error {
+		if index <= 0 || index > len(items) {
+			return errors.New(voice.Line("invalid_index"))
+		}
+		items = append(items[:index-1], items[index:]...)
+		return nil
+	})
 }
 
 func NewLog(server Server, action string, err error) LogEntry {
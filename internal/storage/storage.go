@@ -0,0 +1,295 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sakibox/internal/voice"
+)
+
+type Migration func(data json.RawMessage) (json.RawMessage, error)
+
+type Store struct {
+	Name       string
+	Version    int
+	Migrations map[int]Migration
+	Backups    int
+	Perm       os.FileMode
+}
+
+type envelope struct {
+	Version int             `json:"sakibox_version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+const defaultBackups = 5
+
+var ErrUnchanged = errors.New("storage: unchanged")
+
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".sakibox"), nil
+}
+
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+func (s Store) Path() (string, error) {
+	return Path(s.Name)
+}
+
+func (s Store) Load(v any) error {
+	path, err := s.Path()
+	if err != nil {
+		return err
+	}
+	unlock, err := Lock(path, false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	_, err = s.read(path, v)
+	return err
+}
+
+func (s Store) Save(v any) error {
+	path, err := s.Path()
+	if err != nil {
+		return err
+	}
+	unlock, err := Lock(path, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return s.write(path, v)
+}
+
+func (s Store) Update(v any, fn func() error) error {
+	path, err := s.Path()
+	if err != nil {
+		return err
+	}
+	unlock, err := Lock(path, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if _, err := s.read(path, v); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		if errors.Is(err, ErrUnchanged) {
+			return nil
+		}
+		return err
+	}
+	return s.write(path, v)
+}
+
+func (s Store) Decode(raw []byte) (json.RawMessage, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	version := 0
+	data := json.RawMessage(raw)
+	if env, ok := parseEnvelope(raw); ok {
+		version, data = env.Version, env.Data
+	}
+	if version > s.Version {
+		return nil, errors.New(voice.Linef("storage_newer_version", s.Name, version))
+	}
+	for ; version < s.Version; version++ {
+		migrate, ok := s.Migrations[version]
+		if !ok {
+			continue
+		}
+		migrated, err := migrate(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: migrate v%d: %w", s.Name, version, err)
+		}
+		data = migrated
+	}
+	return data, nil
+}
+
+func (s Store) Encode(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(envelope{Version: s.Version, Data: data}, "", "  ")
+}
+
+func parseEnvelope(raw []byte) (envelope, bool) {
+	if raw[0] != '{' {
+		return envelope{}, false
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return envelope{}, false
+	}
+	if _, ok := fields["sakibox_version"]; !ok {
+		return envelope{}, false
+	}
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return envelope{}, false
+	}
+	return env, true
+}
+
+func (s Store) read(path string, v any) (bool, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	data, err := s.Decode(raw)
+	if err != nil {
+		return false, err
+	}
+	if len(data) == 0 || string(data) == "null" {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+func (s Store) write(path string, v any) error {
+	payload, err := s.Encode(v)
+	if err != nil {
+		return err
+	}
+	if err := s.backup(path); err != nil {
+		return err
+	}
+	perm := s.Perm
+	if perm == 0 {
+		perm = 0644
+	}
+	return WriteFileAtomic(path, payload, perm)
+}
+
+func (s Store) backup(path string) error {
+	limit := s.Backups
+	if limit == 0 {
+		limit = defaultBackups
+	}
+	if limit < 0 {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	dir, err := Path("backups")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s.%s", s.Name, time.Now().Format("20060102-150405.000000"))
+	if err := WriteFileAtomic(filepath.Join(dir, name), data, 0600); err != nil {
+		return err
+	}
+	return rotate(s.Name, limit)
+}
+
+func Backups(name string) ([]string, error) {
+	dir, err := Path("backups")
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	backups := make([]string, 0)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), name+".") {
+			backups = append(backups, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+func rotate(name string, limit int) error {
+	backups, err := Backups(name)
+	if err != nil {
+		return err
+	}
+	for len(backups) > limit {
+		if err := os.Remove(backups[0]); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	name := tmp.Name()
+	cleanup := func() {
+		_ = tmp.Close()
+		_ = os.Remove(name)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		cleanup()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		cleanup()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		cleanup()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(name)
+		return err
+	}
+	if err := os.Rename(name, path); err != nil {
+		_ = os.Remove(name)
+		return err
+	}
+	if handle, err := os.Open(dir); err == nil {
+		_ = handle.Sync()
+		_ = handle.Close()
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	store := Store{
+		Name:    "items",
+		Version: 2,
+		Migrations: map[int]Migration{
+			0: func(data json.RawMessage) (json.RawMessage, error) {
+				return json.RawMessage(`{"v0":` + string(data) + `}`), nil
+			},
+			1: func(data json.RawMessage) (json.RawMessage, error) {
+				return json.RawMessage(`{"v1":` + string(data) + `}`), nil
+			},
+		},
+	}
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"empty", "  \n", ""},
+		{"bare list migrates from zero", `[1]`, `{"v1":{"v0":[1]}}`},
+		{"bare object migrates from zero", `{"a":1}`, `{"v1":{"v0":{"a":1}}}`},
+		{"old version key is plain data", `{"version":2,"data":[1]}`, `{"v1":{"v0":{"version":2,"data":[1]}}}`},
+		{"envelope at version one", `{"sakibox_version":1,"data":[1]}`, `{"v1":[1]}`},
+		{"current envelope", `{"sakibox_version":2,"data":[1]}`, `[1]`},
+		{"envelope with padding", "\n {\"sakibox_version\": 2, \"data\": {\"a\": 1}} \n", `{"a": 1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.Decode([]byte(tt.raw))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Decode(%q) = %s, want %s", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	failing := errors.New("boom")
+	store := Store{
+		Name:    "items",
+		Version: 1,
+		Migrations: map[int]Migration{
+			0: func(json.RawMessage) (json.RawMessage, error) { return nil, failing },
+		},
+	}
+	if _, err := store.Decode([]byte(`{"sakibox_version":2,"data":[]}`)); err == nil {
+		t.Error("Decode of a newer version succeeded, want error")
+	}
+	if _, err := store.Decode([]byte(`[]`)); !errors.Is(err, failing) {
+		t.Errorf("Decode with a failing migration = %v, want %v", err, failing)
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	store := Store{Name: "items", Version: 3}
+	raw, err := store.Encode([]string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), `"sakibox_version": 3`) {
+		t.Errorf("Encode = %s, want a sakibox_version envelope", raw)
+	}
+	data, err := store.Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []string
+	if err := json.Unmarshal(data, &items); err != nil || len(items) != 2 || items[1] != "b" {
+		t.Errorf("round trip = %s (%v), want [a b]", data, err)
+	}
+}
@@ -803,4 +803,833 @@ var lines = map[string][]string{
 		"日志为空。",
 		"暂时没有日志记录。",
 	},
+	"command_edit_hint": {
+		"可以先修改命令，回车执行，Esc 取消。",
+		"命令已经备好，想改就改吧，回车执行，Esc 取消。",
+		"请再确认一次命令，回车执行，Esc 取消。",
+		"若需要调整，请在这里修改，回车执行，Esc 取消。",
+	},
+	"command_edit_cancel": {
+		"好的，这次就先不执行了。",
+		"已取消执行。",
+		"执行已取消，命令还在原处等你。",
+		"那就先停下吧，已取消执行。",
+	},
+	"command_preview": {
+		"即将执行: %s",
+		"准备执行: %s",
+		"马上为你执行: %s",
+		"这就执行: %s",
+	},
+	"placeholder_intro": {
+		"这条命令里有需要填写的参数。",
+		"命令里还留着几处空白，请一一填写。",
+		"请为命令补全这些参数吧。",
+		"还差几个参数，告诉我就好。",
+	},
+	"placeholder_prompt": {
+		"请填写 %s: ",
+		"请告诉我 %s: ",
+		"请输入 %s: ",
+		"请告知 %s: ",
+	},
+	"placeholder_empty": {
+		"参数不能为空呢。",
+		"这个参数还是空的，已取消执行。",
+		"缺少参数，这次先不执行了。",
+		"参数为空，请再试一次吧。",
+	},
+	"history_stats_empty": {
+		"还没有可以统计的历史命令呢。",
+		"历史记录是空的，暂时无从统计。",
+		"没有找到历史命令，统计只好作罢。",
+	},
+	"history_stats_total": {
+		"共统计了 %d 条命令：",
+		"这里记录着 %d 条命令的足迹：",
+		"一共 %d 条命令，都在这里了：",
+		"%d 条命令的轨迹，请过目：",
+	},
+	"history_stats_no_failures": {
+		"暂无失败记录（通过 sakibox 执行的命令会记录退出码）。",
+		"还没有失败的记录呢。",
+		"没有可用的退出码记录。",
+	},
+	"history_stats_no_time": {
+		"历史文件没有记录时间，无法绘制热力图。",
+		"这份历史没有时间戳，热力图只好留白。",
+		"缺少时间信息，热力图暂时无法显示。",
+	},
+	"history_stats_done": {
+		"统计就是这些了，也许能帮你决定收藏什么。",
+		"这些就是你的使用习惯了。",
+		"统计完毕，常用的命令不妨收藏起来吧。",
+		"就是这些了，愿它们帮你找到下一个别名。",
+	},
+	"history_secret_scanning": {
+		"正在检查历史记录里是否藏着秘密...",
+		"正在轻轻翻检历史记录，请稍候...",
+		"让我看看历史里有没有不该留下的东西...",
+	},
+	"history_secret_clean": {
+		"没有发现可疑的敏感信息，很干净呢。",
+		"历史记录里没有发现秘密，请放心。",
+		"一切安好，没有找到敏感信息。",
+	},
+	"history_secret_found": {
+		"发现 %d 处疑似敏感信息。",
+		"有 %d 处内容看起来像是秘密。",
+		"共找到 %d 处需要留意的地方。",
+	},
+	"history_secret_confirm": {
+		"要先备份再把它们替换为掩码吗？(y/n): ",
+		"是否备份原文件并遮盖这些内容？(y/n): ",
+		"确认备份后进行遮盖吗？(y/n): ",
+	},
+	"history_secret_cancel": {
+		"好的，历史文件保持原样。",
+		"已取消，没有修改任何文件。",
+		"那就先不动它们了。",
+	},
+	"history_secret_redacted": {
+		"%s 已遮盖 %d 行，备份在 %s",
+		"已处理 %s 的 %d 行，原文件备份于 %s",
+		"%s 中的 %d 行已替换为掩码，备份: %s",
+	},
+	"history_secret_shell_hint": {
+		"提示：正在运行的 shell 可能会在退出时写回旧记录，请重新打开终端或清空其内存中的历史。",
+		"请注意，已打开的终端仍保留着旧历史，退出时可能写回，建议重启终端。",
+		"别忘了重启终端，否则内存里的旧历史可能会被写回文件。",
+	},
+	"run_summary": {
+		"  退出码 %d，用时 %s",
+		"  执行结束：退出码 %d，耗时 %s",
+		"  已结束，退出码 %d，用时 %s",
+	},
+	"run_intro": {
+		"这些是最近执行过的命令。",
+		"最近的每一次执行，我都记着呢。",
+		"执行的足迹都在这里了。",
+	},
+	"run_empty": {
+		"还没有运行记录呢。",
+		"暂时没有执行过的命令。",
+		"运行记录是空的。",
+	},
+	"run_select_prompt": {
+		"输入序号查看详情（回车返回）: ",
+		"请告诉我要查看的记录序号（回车返回）: ",
+		"想看哪一次呢？输入序号（回车返回）: ",
+	},
+	"run_no_output": {
+		"这次运行没有保存输出（可在 config.yaml 中开启 capture_output）。",
+		"没有留下输出记录，开启 capture_output 后会保存。",
+		"输出未被记录，需要时请开启 capture_output。",
+	},
+	"bookmark_empty": {
+		"收藏夹还是空的呢。",
+		"还没有收藏任何命令。",
+		"这里暂时空空如也。",
+	},
+	"bookmark_no_folder": {
+		"未分组",
+		"未分组",
+		"未分组",
+	},
+	"bookmark_name_exists": {
+		"已经有同名的收藏了，换个名字吧。",
+		"这个名字已被使用，请换一个。",
+		"同名收藏已存在呢。",
+	},
+	"bookmark_add_desc_prompt": {
+		"请写一句描述（可回车跳过）: ",
+		"请为它留下说明（回车跳过）: ",
+		"描述一下这条命令吧（回车跳过）: ",
+	},
+	"bookmark_add_tags_prompt": {
+		"请输入标签，用逗号分隔（可回车跳过）: ",
+		"请告诉我标签，逗号分隔（回车跳过）: ",
+		"要加上哪些标签呢？逗号分隔（回车跳过）: ",
+	},
+	"bookmark_add_folder_prompt": {
+		"请输入分组（可回车跳过）: ",
+		"要放进哪个分组呢（回车跳过）: ",
+		"请告知所属分组（回车跳过）: ",
+	},
+	"bookmark_add_dir_prompt": {
+		"请输入执行目录（回车表示当前目录）: ",
+		"要在哪个目录执行呢（回车表示当前目录）: ",
+		"请告知工作目录（回车表示当前目录）: ",
+	},
+	"bookmark_filter_tag_prompt": {
+		"按标签筛选（回车跳过）: ",
+		"请输入要筛选的标签（回车跳过）: ",
+		"想看哪个标签呢（回车跳过）: ",
+	},
+	"bookmark_filter_folder_prompt": {
+		"按分组筛选（回车跳过）: ",
+		"请输入要筛选的分组（回车跳过）: ",
+		"想看哪个分组呢（回车跳过）: ",
+	},
+	"bookmark_sort_condition": {
+		"排序方式：1.默认 2.使用次数 3.最近使用",
+		"请选择排序：1.默认 2.使用次数 3.最近使用",
+		"请选定排序：1.默认 2.使用次数 3.最近使用",
+	},
+	"bookmark_edit_prompt": {
+		"请输入要编辑的收藏序号或名称: ",
+		"请告诉我要修改哪条收藏: ",
+		"请告知要编辑的收藏: ",
+	},
+	"bookmark_edit_hint": {
+		"逐项修改，回车保留当前内容，Esc 取消。",
+		"请逐项确认，回车即保留，Esc 取消。",
+		"慢慢修改吧，回车保留，Esc 取消。",
+	},
+	"bookmark_edit_cancel": {
+		"已取消编辑。",
+		"好的，保持原样。",
+		"编辑已取消。",
+	},
+	"bookmark_edit_success": {
+		"收藏已更新。",
+		"修改完成了。",
+		"已为你更新这条收藏。",
+	},
+	"bookmark_project_title": {
+		"项目收藏",
+		"项目收藏",
+		"项目收藏",
+	},
+	"bookmark_project_readonly": {
+		"这是项目收藏，请直接编辑 %s 。",
+		"项目收藏由 %s 管理，请在那里修改。",
+		"它来自 %s ，请在项目文件中调整。",
+	},
+	"bookmark_import_prompt": {
+		"请输入序号或文件路径（回车导入以上全部）: ",
+		"请告诉我从哪里导入，序号或路径（回车全部）: ",
+		"从哪份文件导入呢？序号或路径（回车全部）: ",
+	},
+	"bookmark_import_empty": {
+		"没有找到可以导入的命令。",
+		"这里没有可导入的内容呢。",
+		"暂时没有可导入的命令。",
+	},
+	"bookmark_import_confirm": {
+		"共解析出 %d 条命令，确认导入吗？(y/n): ",
+		"找到 %d 条命令，要收进收藏夹吗？(y/n): ",
+		"这 %d 条命令要一起导入吗？(y/n): ",
+	},
+	"bookmark_import_cancel": {
+		"已取消导入。",
+		"好的，这次先不导入。",
+		"导入已取消。",
+	},
+	"bookmark_import_success": {
+		"导入完成：新增 %d 条，跳过 %d 条同名或无效项。",
+		"已收下 %d 条命令，另有 %d 条因重名或无效而跳过。",
+		"新增 %d 条收藏，跳过 %d 条。",
+	},
+	"bookmark_export_format": {
+		"导出格式：1.shell 别名文件 2.JSON 3.YAML",
+		"请选择格式：1.shell 别名文件 2.JSON 3.YAML",
+		"请选定格式：1.shell 别名文件 2.JSON 3.YAML",
+	},
+	"bookmark_export_path_prompt": {
+		"导出路径（回车使用 %s）: ",
+		"请告诉我导出到哪里（回车为 %s）: ",
+		"请输入导出路径（回车使用 %s）: ",
+	},
+	"bookmark_export_success": {
+		"已导出 %d 条收藏到 %s",
+		"%d 条收藏已写入 %s",
+		"导出完成，共 %d 条，位于 %s",
+	},
+	"bookmark_export_source_hint": {
+		"在 shell 配置中加入 `source %s` 即可使用这些别名。",
+		"把 `source %s` 写进 ~/.bashrc 或 ~/.zshrc 就能用上它们了。",
+		"请在 rc 文件中 source %s ，别名便会生效。",
+	},
+	"workflow_title": {
+		"工作流",
+		"工作流",
+		"工作流",
+	},
+	"workflow_summary": {
+		"工作流（%d 步）",
+		"工作流（%d 步）",
+		"工作流（%d 步）",
+	},
+	"workflow_confirm": {
+		"%s (y=执行 n=跳过 q=中止): ",
+		"%s (y 执行 / n 跳过 / q 中止): ",
+		"%s 请确认 (y=执行 n=跳过 q=中止): ",
+	},
+	"workflow_step_ok": {
+		"这一步完成了。",
+		"顺利完成。",
+		"这一步已经妥当。",
+	},
+	"workflow_step_skipped": {
+		"已跳过这一步。",
+		"这一步先跳过了。",
+		"好的，跳过此步。",
+	},
+	"workflow_stopped": {
+		"这一步失败了，后续步骤不再执行。",
+		"出错了，我先停下后面的步骤。",
+		"步骤失败，工作流已停止。",
+	},
+	"workflow_failed": {
+		"工作流没有全部完成，请查看上面的结果。",
+		"有步骤未能完成，请留意上面的汇总。",
+		"工作流中途受阻，请检查失败的步骤。",
+	},
+	"workflow_done": {
+		"工作流全部完成，辛苦了。",
+		"所有步骤都已顺利完成。",
+		"一步一步，终于都完成了。",
+	},
+	"workflow_steps_hint": {
+		"接下来逐步添加命令，命令留空即结束。",
+		"请依次告诉我每一步，命令为空时结束。",
+		"一步一步来吧，命令留空便完成添加。",
+	},
+	"workflow_step_cmd_prompt": {
+		"请输入这一步的命令（回车结束）: ",
+		"这一步要执行什么命令（回车结束）: ",
+		"请告知这一步的命令（回车结束）: ",
+	},
+	"workflow_step_name_prompt": {
+		"请为这一步命名（可回车跳过）: ",
+		"这一步叫什么呢（回车跳过）: ",
+		"请输入步骤名称（回车跳过）: ",
+	},
+	"workflow_step_dir_prompt": {
+		"执行目录（回车沿用收藏目录）: ",
+		"这一步在哪个目录执行（回车沿用）: ",
+		"请输入执行目录（回车沿用）: ",
+	},
+	"workflow_step_env_prompt": {
+		"环境变量，如 KEY=VALUE，空格分隔（回车跳过）: ",
+		"需要哪些环境变量？KEY=VALUE 空格分隔（回车跳过）: ",
+		"请输入环境变量 KEY=VALUE（回车跳过）: ",
+	},
+	"workflow_step_continue_prompt": {
+		"失败后是否继续后续步骤？(y/n): ",
+		"这一步出错时还要继续吗？(y/n): ",
+		"失败也继续吗？(y/n): ",
+	},
+	"workflow_step_confirm_prompt": {
+		"执行前的确认提示（回车表示无需确认）: ",
+		"需要执行前确认吗？请输入提示语（回车跳过）: ",
+		"请输入确认提示（回车表示不确认）: ",
+	},
+	"workflow_add_success": {
+		"工作流已收藏，共 %d 步。",
+		"已保存这个 %d 步的工作流。",
+		"工作流收好了，一共 %d 步。",
+	},
+	"storage_newer_version": {
+		"%s 是更新版本的 sakibox 写入的 (v%d)，小萨不敢乱动它。",
+		"%s 的格式版本 v%d 比我认识的新，先升级一下 sakibox 吧。",
+	},
+	"sync_no_remote": {
+		"还没有配置同步仓库，用 sakibox sync --remote <地址> 设置一下吧。",
+		"没有 sync_remote，先执行 sakibox sync --remote <地址>。",
+	},
+	"sync_no_git": {
+		"没有找到 git，同步需要先安装 git 哦。",
+		"同步依赖 git，请先安装。",
+	},
+	"sync_intro": {
+		"正在与 %s 同步 (%s)...",
+		"小萨开始同步 %s: %s",
+	},
+	"sync_failed": {
+		"同步失败，先用本地数据继续: %v",
+		"同步没成功 (%v)，这次先不管它。",
+	},
+	"sync_timeout": {
+		"同步超过 %s 仍未完成，已跳过，可以稍后手动运行 sakibox sync。",
+		"远程仓库 %s 内没有回应，这次先不同步了。",
+	},
+	"sync_updated": {
+		"已从远端合并: %s",
+		"本地已更新: %s",
+	},
+	"sync_conflict": {
+		"冲突，保留本地版本:",
+		"两边都改了，以本地为准:",
+	},
+	"sync_pushed": {
+		"本地改动已推送到远端。",
+		"已推送到远端。",
+	},
+	"sync_up_to_date": {
+		"已经是最新的了。",
+		"两边一致，无需同步。",
+	},
+	"searching_progress": {
+		"已搜索 %d/%d 个文件，命中 %d 处 · %s",
+		"正在检索 %d/%d 个文件，找到 %d 处 · %s",
+	},
+	"finder_archive_failed": {
+		"有 %d 个压缩包无法读取，已跳过（.xz 格式需要系统中安装 xz 命令）。",
+		"%d 个压缩包没能打开，跳过了；如果是 .xz，看看有没有装 xz 命令。",
+	},
+	"finder_content_summary": {
+		"共 %d 处匹配，搜索了 %d 个文件（跳过 %d 个二进制文件）。",
+		"在 %[2]d 个文件里找到 %[1]d 处匹配，另有 %[3]d 个二进制文件被跳过。",
+	},
+	"finder_content_flags_prompt": {
+		"搜索选项（r 正则 i 忽略大小写 w 整词 v 反向 z 压缩包 c2/b2/a2 上下文行，回车跳过）: ",
+		"可选: r=正则 i=忽略大小写 w=整词 v=反向匹配 z=搜索压缩包 c<N>/b<N>/a<N>=上下文行数（直接回车跳过）: ",
+	},
+	"finder_invalid_flag": {
+		"不认识的搜索选项: %s",
+		"选项 %s 小萨看不懂呢。",
+	},
+	"finder_invalid_regex": {
+		"正则表达式写得不太对",
+		"这个正则有点问题",
+	},
+	"finder_scope_intro": {
+		"这些设置会保存到 config.yaml，对所有查找生效。",
+		"调整查找时要不要看隐藏目录、要不要遵循忽略规则、要不要搜索压缩包、要不要使用文件索引。",
+	},
+	"finder_scope_saved": {
+		"设置已保存。",
+		"好的，已经记下了。",
+	},
+	"finder_query_help": {
+		"用一句话组合条件：name/ext/path/type/content 与 size、mtime 比较，空格表示 AND，还可以用 OR、NOT、-前缀和括号，例如:",
+		"组合查询支持 name: ext: path: type: content: 以及 size> mtime< 等条件，空格即 AND，另有 OR / NOT / - / 括号，比如:",
+	},
+	"finder_query_prompt": {
+		"请输入查询语句: ",
+		"说说你的查询条件: ",
+	},
+	"finder_query_invalid": {
+		"查询语句有误: %s",
+		"这句查询小萨没看懂: %s",
+	},
+	"finder_query_empty": {
+		"查询为空",
+		"什么条件都没写",
+	},
+	"finder_query_unexpected": {
+		"多出来的 %s",
+		"不该出现的 %s",
+	},
+	"finder_query_unclosed": {
+		"引号、正则或括号没有闭合",
+		"有个引号、斜杠或括号没配对",
+	},
+	"finder_query_missing_term": {
+		"运算符两边缺少条件",
+		"AND/OR/NOT 旁边少了条件",
+	},
+	"finder_query_missing_value": {
+		"%s: 后面需要写值",
+		"%s: 还缺一个值",
+	},
+	"finder_query_unknown": {
+		"不认识的条件 %s",
+		"条件 %s 不在支持范围内",
+	},
+	"finder_query_bad_time": {
+		"时间写法不对: %s（例如 7d、12h、2w 或 2024-01-01）",
+		"看不懂的时间 %s，可以写 7d、12h、2w 或 2024-01-01",
+	},
+	"trash_empty": {
+		"回收站里没有可以恢复的内容。",
+		"回收站是空的，没有什么可以撤销的。",
+		"暂时没有可以恢复的删除记录。",
+	},
+	"trash_restore_exists": {
+		"原位置已经有 %s 了，先不覆盖它。",
+		"%s 已经存在，无法恢复到原处。",
+		"恢复失败：%s 已被占用。",
+	},
+	"fileops_exists": {
+		"目标已存在，跳过: %s",
+		"%s 已经存在了，先跳过它。",
+		"跳过已存在的目标: %s",
+	},
+	"finder_actions_prompt": {
+		"要对结果做些什么？回车返回: ",
+		"请选择操作，回车返回: ",
+		"需要处理这些结果吗？回车返回: ",
+	},
+	"finder_select_prompt": {
+		"请输入序号（如 1,3,5-8，a 为全部）: ",
+		"要选哪些呢？（如 1,3,5-8，a 为全部）: ",
+		"请告诉我序号（如 1,3,5-8，a 为全部）: ",
+	},
+	"finder_select_invalid": {
+		"序号 %s 无效。",
+		"没有找到序号 %s。",
+		"%s 不是有效的序号呢。",
+	},
+	"finder_select_empty": {
+		"没有选中任何项目。",
+		"什么都没有选中呢。",
+		"还没有选择项目。",
+	},
+	"finder_action_preview": {
+		"预演：将%s以下 %d 项，共 %s",
+		"先预览一下：%s %d 项，合计 %s",
+		"以下 %[2]d 项将被%[1]s，共 %[3]s",
+	},
+	"finder_action_confirm": {
+		"确认执行吗？(y/n): ",
+		"要继续吗？(y/n): ",
+		"确认无误就开始吧？(y/n): ",
+	},
+	"finder_action_cancel": {
+		"已取消，什么都没有改动。",
+		"好的，先不动它们。",
+		"明白了，保持原样。",
+	},
+	"finder_target_prompt": {
+		"请输入目标目录: ",
+		"要放到哪个目录呢？: ",
+		"请告诉我目标目录: ",
+	},
+	"finder_archive_prompt": {
+		"请输入归档路径（回车使用 %s）: ",
+		"要保存到哪里呢？（回车使用 %s）: ",
+		"归档文件路径（默认 %s）: ",
+	},
+	"finder_trash_done": {
+		"已将 %d 项移入回收站，可以用 u 撤销。",
+		"%d 项已放进回收站，需要的话按 u 撤销。",
+		"回收站收下了 %d 项，u 可以撤销哦。",
+	},
+	"finder_trash_undone": {
+		"已恢复 %d 项到原位置。",
+		"%d 项已经放回原处了。",
+		"撤销完成，恢复了 %d 项。",
+	},
+	"finder_move_done": {
+		"已移动 %d 项到 %s",
+		"%d 项已经搬到 %s 了。",
+		"移动完成：%d 项 → %s",
+	},
+	"finder_copy_done": {
+		"已复制 %d 项到 %s",
+		"%d 项已经复制到 %s 了。",
+		"复制完成：%d 项 → %s",
+	},
+	"finder_archive_done": {
+		"已打包 %d 个文件到 %s",
+		"%d 个文件已经收进 %s 了。",
+		"打包完成：%d 个文件 → %s",
+	},
+	"finder_total_size": {
+		"共 %d 个文件，合计 %s",
+		"一共 %d 个文件，总大小 %s",
+		"统计完成：%d 个文件，%s",
+	},
+	"finder_target_empty": {
+		"目标不能为空。",
+		"还没有告诉我目标在哪里呢。",
+		"请先输入目标路径。",
+	},
+	"finder_index_info": {
+		"索引: %d 个文件，%d 个目录，建立于 %s",
+		"当前索引收录了 %d 个文件、%d 个目录（%s 建立）",
+	},
+	"finder_index_building": {
+		"正在重建文件索引，请稍候...",
+		"正在为主目录整理索引，请稍等...",
+	},
+	"finder_index_built": {
+		"索引已重建：%d 个文件，%d 个目录，用时 %s",
+		"整理好了：%d 个文件、%d 个目录，花了 %s",
+	},
+	"finder_search_cancelled": {
+		"搜索已中断，下面是中断前找到的结果。",
+		"好，停下来了。先看看已经找到的吧。",
+	},
+	"finder_search_timeout": {
+		"到搜索时限了，只列出时限内找到的结果。",
+		"时间到，先看这些吧。想搜得更久可以在搜索范围设置里调整时限。",
+	},
+	"finder_search_limit": {
+		"已达到最多结果数，后面的就不找了。",
+		"结果够多了，先停在这里。上限可以在搜索范围设置里调整。",
+	},
+	"finder_depth_prompt": {
+		"最大搜索深度（层数，0 或留空为不限）：",
+		"最多往下找几层？（0 或留空不限）：",
+	},
+	"finder_max_results_prompt": {
+		"最多结果数（0 或留空为不限）：",
+		"找到多少条就停？（0 或留空不限）：",
+	},
+	"finder_timeout_prompt": {
+		"搜索时限（秒，0 或留空为不限）：",
+		"每次搜索最多花几秒？（0 或留空不限）：",
+	},
+	"finder_invalid_limit": {
+		"请输入不小于 0 的整数。",
+		"这个数不太对，要 0 或正整数哦。",
+	},
+	"finder_dup_min_prompt": {
+		"只看不小于多大的文件？（如 1M，回车不限）: ",
+		"最小文件大小（如 10M，回车不限）: ",
+		"请告诉我最小的文件大小（如 1M，回车不限）: ",
+	},
+	"finder_dup_progress": {
+		"已收集 %d 个文件，校验中 %d/%d",
+		"扫描了 %d 个文件，正在比对 %d/%d",
+		"%d 个文件已登记，核对进度 %d/%d",
+	},
+	"finder_dup_none": {
+		"没有发现重复的文件。",
+		"这里的文件都是独一无二的呢。",
+		"没有找到内容相同的文件。",
+	},
+	"finder_dup_wasted": {
+		"可释放 %s",
+		"多占了 %s",
+		"浪费 %s",
+	},
+	"finder_dup_summary": {
+		"共 %d 组重复文件，可释放 %s。",
+		"找到 %d 组内容相同的文件，一共多占了 %s。",
+		"%d 组重复，整理后能腾出 %s。",
+	},
+	"finder_dup_select_prompt": {
+		"请选择要处理的组（如 1,3,5-8，a 为全部）: ",
+		"要整理哪几组呢？（如 1,3,5-8，a 为全部）: ",
+		"请告诉我组号（如 1,3,5-8，a 为全部）: ",
+	},
+	"finder_dup_preview": {
+		"预演：处理 %d 组，预计释放 %s",
+		"先预览一下：%d 组，大约能腾出 %s",
+		"以下 %d 组将被整理，预计释放 %s",
+	},
+	"finder_dup_all_handled": {
+		"重复文件都处理完了。",
+		"所有重复组都清理干净啦。",
+	},
+	"finder_dup_linked": {
+		"已将 %d 个重复文件替换为硬链接。",
+		"%d 个副本已经换成了硬链接。",
+		"硬链接完成，处理了 %d 个文件。",
+	},
+	"finder_usage_scanning": {
+		"正在统计磁盘占用，请稍候...",
+		"正在计算每个目录的大小，请稍等...",
+		"让我数一数这里的空间都去哪了...",
+	},
+	"finder_usage_progress": {
+		"已统计 %d 项，%s，当前 %s",
+		"数到了 %d 项（%s），正在看 %s",
+		"%d 项，共 %s，%s",
+	},
+	"finder_usage_total": {
+		"%s 共 %s，%d 个文件",
+		"%s 合计 %s，包含 %d 个文件",
+	},
+	"finder_usage_empty": {
+		"这里是空的。",
+		"这个目录里什么都没有。",
+	},
+	"finder_usage_mount": {
+		"(其他文件系统)",
+		"(挂载点，未统计)",
+	},
+	"finder_usage_failed": {
+		"(无法读取)",
+		"(没有权限)",
+	},
+	"finder_usage_delete_confirm": {
+		"永久删除 %s（%s）？删除后无法恢复，按 y 确认，其他键取消",
+		"真的要永久删掉 %s 吗？它占了 %s，删了就找不回来了。y 确认，其他键取消",
+	},
+	"finder_usage_deleted": {
+		"已永久删除 %s，释放 %s",
+		"%s 删掉了，腾出了 %s",
+	},
+	"finder_usage_delete_partial": {
+		"只删除了一部分，释放 %s：%v",
+		"没能全部删掉，腾出了 %s：%v",
+	},
+	"finder_usage_no_delete_mount": {
+		"%s 是其他文件系统的挂载点（或包含挂载点），不能在这里删除。",
+		"%s 里挂着别的文件系统，这里不删它。",
+	},
+	"finder_usage_no_delete_unread": {
+		"%s 有内容没能统计到，不能在这里删除。",
+		"%s 还有没读到的部分，为了安全不删它。",
+	},
+	"finder_usage_partial": {
+		"(统计被中断，结果不完整)",
+		"(没统计完，大小仅供参考)",
+	},
+	"finder_usage_unscanned": {
+		"(未统计)",
+		"(还没数到)",
+	},
+	"finder_usage_size": {
+		"共 %s，%d 个文件",
+		"合计 %s，包含 %d 个文件",
+	},
+	"finder_struct_bad_path": {
+		"键路径 %s 写得不太对。",
+		"看不懂键路径 %s 呢。",
+	},
+	"finder_struct_bad_toml": {
+		"TOML 第 %d 行解析失败。",
+		"TOML 在第 %d 行读不下去了。",
+	},
+	"finder_struct_help": {
+		"按键路径或取值在 JSON / YAML / TOML 文件里查找，例如：",
+		"可以用键路径、取值或两者一起查找，例如：",
+	},
+	"finder_struct_path_prompt": {
+		"键路径（回车匹配任意键）: ",
+		"要查的键路径（回车不限）: ",
+	},
+	"finder_struct_value_prompt": {
+		"取值包含（支持 * 通配，回车不限）: ",
+		"值里要包含什么？（支持 * 通配，回车不限）: ",
+	},
+	"finder_struct_need_query": {
+		"键路径和取值至少要填一个。",
+		"至少告诉我键路径或取值其中之一吧。",
+	},
+	"finder_struct_summary": {
+		"在 %d 个文件里找到 %d 处。",
+		"%d 个文件中共有 %d 处匹配。",
+	},
+	"finder_replace_prompt": {
+		"替换为（正则模式下可用 $1 引用分组）: ",
+		"要替换成什么？（正则模式可写 $1、${name}）: ",
+	},
+	"finder_replace_flags_prompt": {
+		"替换选项（r 正则 i 忽略大小写 w 整词，回车跳过）: ",
+		"可选: r=正则 i=忽略大小写 w=整词（直接回车跳过）: ",
+	},
+	"finder_replace_invert": {
+		"反向匹配没法用来替换哦。",
+		"替换不支持 v 反向匹配。",
+	},
+	"finder_replace_incomplete": {
+		"搜索没有完整跑完，为免漏改，这次不做替换。",
+		"没搜完就不替换了，免得改一半。可以缩小范围再试。",
+	},
+	"finder_replace_summary": {
+		"将修改 %d 个文件，%d 处改动块，共 %d 行。",
+		"预览完毕：%d 个文件、%d 个改动块、%d 行会被替换。",
+	},
+	"finder_replace_file_prompt": {
+		"应用这个文件的修改吗？[%d/%d] (y/n/q): ",
+		"这个文件要改吗？[%d/%d] (y=是 n=跳过 q=结束): ",
+	},
+	"finder_replace_hunk_prompt": {
+		"应用这一处修改吗？[%d/%d] (y/n/q): ",
+		"这一块要改吗？[%d/%d] (y=是 n=跳过 q=结束): ",
+	},
+	"finder_replace_stale": {
+		"%s 在预览之后被改动过，已跳过。",
+		"%s 预览后内容变了，这次先不动它。",
+	},
+	"finder_replace_done": {
+		"已修改 %d 个文件，可以在菜单里撤销上次替换。",
+		"%d 个文件替换完成，后悔的话可以撤销。",
+	},
+	"finder_replace_nothing": {
+		"没有可以撤销的替换记录。",
+		"还没有替换过，没什么可撤销的。",
+	},
+	"finder_replace_last": {
+		"上次替换（%s）：%q → %q，涉及 %d 个文件：",
+		"最近一次替换在 %s，把 %q 换成了 %q，共 %d 个文件：",
+	},
+	"finder_replace_modified": {
+		"%s 在替换之后又被修改过，为了不丢掉新的改动，先不撤销。",
+		"%s 替换后又有新改动，撤销会覆盖它们，已停止。",
+	},
+	"finder_replace_undone": {
+		"已恢复 %d 个文件。",
+		"撤销完成，%d 个文件回到了替换前的样子。",
+	},
+	"finder_watch_started": {
+		"正在监视 %s，按 Ctrl-C 结束。",
+		"开始盯着 %s 了，Ctrl-C 退出。",
+	},
+	"finder_watch_exec_failed": {
+		"命令执行失败：%v",
+		"--exec 命令出错了：%v",
+	},
+	"finder_watch_skipped": {
+		"%s 在命令运行期间或刚结束时又有变化，多半是命令自己写的，这次不再触发命令。",
+		"%s 的这次变化离上次执行太近，可能是命令自己改的，跳过不执行。",
+	},
+	"finder_watch_coalesced": {
+		"%s 已在等待执行，合并为一次，按最新的事件运行命令。",
+		"%s 还在排队，这次变化并进去了，只按最新的事件跑一次。",
+	},
+	"finder_watch_overflow": {
+		"事件太多，内核丢弃了一部分，正在重新扫描目录。",
+		"变化来得太快，有些事件没接住，重新扫一遍目录补上。",
+	},
+	"finder_watch_limit": {
+		"inotify 监视数量已达上限，可以调大 fs.inotify.max_user_watches 或缩小监视范围。",
+		"目录太多，inotify 监视名额用完了；试试调高 fs.inotify.max_user_watches，或者换个小一点的目录。",
+	},
+	"finder_watch_unsupported": {
+		"目录监视目前只支持 Linux。",
+		"这个系统上还不能监视目录（需要 Linux inotify）。",
+	},
+	"tail_no_files": {
+		"选中的都不是普通文件，没法跟踪。",
+		"只能跟踪普通文件，目录不行哦。",
+	},
+	"tail_files": {
+		"%d 个文件",
+		"同时跟踪 %d 个文件",
+	},
+	"tail_paused": {
+		"已暂停，新增 %d 行",
+		"暂停中（%d 行未看）",
+	},
+	"tail_scrolled": {
+		"正在回看，新增 %d 行，按 G 回到末尾",
+		"回看中（%d 行新内容），G 跳到末尾",
+	},
+	"tail_bad_filter": {
+		"过滤表达式有误：%v",
+		"这个正则不太对：%v",
+	},
+	"finder_results_title": {
+		"找到 %d 项",
+		"共 %d 项结果",
+	},
+	"finder_results_matches": {
+		"%d 处匹配",
+		"共 %d 处命中",
+	},
+	"finder_results_sorted": {
+		"按%s排序",
+		"排序：%s",
+	},
+	"finder_results_marked": {
+		"已标记 %d 项",
+		"标记了 %d 项",
+	},
+	"finder_results_chosen": {
+		"接下来的操作只针对标记的 %d 项。",
+		"已选中标记的 %d 项，可以继续操作。",
+	},
+	"finder_preview_binary": {
+		"二进制文件，不显示预览。",
+		"这是二进制文件，就不预览了。",
+	},
 }